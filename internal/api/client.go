@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"unicode"
 )
 
 const BaseURL = "https://www.dnd5eapi.co/api/2014"
@@ -12,9 +13,27 @@ const BaseURL = "https://www.dnd5eapi.co/api/2014"
 // Local API from Docker image
 //const BaseURL = "http://localhost:3000/api/2014"
 
-// ToAPIIndex converts a spell or item name to the API index format (kebab-case)
+// ToAPIIndex converts a spell or item name to the API index format (kebab-case).
+// Apostrophes are dropped and any other run of punctuation becomes a single dash,
+// so "Crossbow, light" is "crossbow-light" and "Thieves' Tools" is "thieves-tools".
 func ToAPIIndex(name string) string {
-	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), " ", "-"))
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		switch {
+		case r == '\'' || r == '’':
+			continue
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			dash = false
+			sb.WriteRune(r)
+		default:
+			dash = true
+		}
+	}
+	return sb.String()
 }
 
 // WeaponEnriched holds extra weapon info from the API
@@ -24,16 +43,61 @@ type WeaponEnriched struct {
 	Range    struct {
 		Normal int `json:"normal"`
 	} `json:"range"`
-	TwoHanded bool `json:"two_handed"`
+	TwoHanded   bool   `json:"two_handed"`
+	WeaponRange string `json:"weapon_range"`
+	Damage      struct {
+		DamageDice string `json:"damage_dice"`
+		DamageType struct {
+			Name string `json:"name"`
+		} `json:"damage_type"`
+	} `json:"damage"`
+	Properties []struct {
+		Name string `json:"name"`
+	} `json:"properties"`
 }
 
 // ArmorEnriched holds extra armor info from the API
 type ArmorEnriched struct {
-	Name       string `json:"name"`
-	ArmorClass struct {
+	Name          string `json:"name"`
+	ArmorCategory string `json:"armor_category"`
+	ArmorClass    struct {
 		Base     int  `json:"base"`
 		DexBonus bool `json:"dex_bonus"`
+		MaxBonus int  `json:"max_bonus"` // 0 when the dexterity bonus is uncapped
 	} `json:"armor_class"`
+	StrMinimum          int  `json:"str_minimum"`
+	StealthDisadvantage bool `json:"stealth_disadvantage"`
+}
+
+// EquipmentEnriched holds the general equipment info from the API, such as cost and weight
+type EquipmentEnriched struct {
+	Name string `json:"name"`
+	Cost struct {
+		Quantity int    `json:"quantity"`
+		Unit     string `json:"unit"`
+	} `json:"cost"`
+	Weight   float64 `json:"weight"`
+	Quantity int     `json:"quantity"` // bundle size, e.g. 20 arrows
+}
+
+// GetEquipment fetches and decodes general equipment details by index (e.g., "rope-hempen-50-feet")
+func GetEquipment(index string) (*EquipmentEnriched, error) {
+	url := fmt.Sprintf("%s/equipment/%s", BaseURL, index)
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API returned status: %s", resp.Status)
+	}
+
+	var item EquipmentEnriched
+	if err := json.NewDecoder(resp.Body).Decode(&item); err != nil {
+		return nil, err
+	}
+	return &item, nil
 }
 
 // GetWeapon fetches and decodes weapon details by index (e.g., "longsword")
@@ -163,6 +227,21 @@ type SpellEnriched struct {
 	School struct {
 		Name string `json:"name"`
 	} `json:"school"`
+	Ritual      bool   `json:"ritual"`
+	CastingTime string `json:"casting_time"` // e.g. "1 action"
+	AttackType  string `json:"attack_type"`  // "melee" or "ranged" for spell attacks
+	Damage      struct {
+		DamageType struct {
+			Name string `json:"name"`
+		} `json:"damage_type"`
+		DamageAtSlotLevel      map[string]string `json:"damage_at_slot_level"`
+		DamageAtCharacterLevel map[string]string `json:"damage_at_character_level"`
+	} `json:"damage"`
+	DC struct {
+		DCType struct {
+			Index string `json:"index"` // Saving throw ability, e.g. "dex"
+		} `json:"dc_type"`
+	} `json:"dc"`
 }
 
 // GetSpell fetches and decodes spell details by index (e.g., "acid-arrow")
@@ -225,3 +304,35 @@ func FetchSpellsWithWorkers(names []string, workerCount int) []*SpellEnriched {
 	}
 	return out
 }
+
+// MagicItemEnriched holds magic item info from the API
+type MagicItemEnriched struct {
+	Name              string `json:"name"`
+	EquipmentCategory struct {
+		Name string `json:"name"`
+	} `json:"equipment_category"`
+	Rarity struct {
+		Name string `json:"name"`
+	} `json:"rarity"`
+	Desc []string `json:"desc"`
+}
+
+// GetMagicItem fetches and decodes magic item details by index (e.g., "cloak-of-protection")
+func GetMagicItem(index string) (*MagicItemEnriched, error) {
+	url := fmt.Sprintf("%s/magic-items/%s", BaseURL, index)
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API returned status: %s", resp.Status)
+	}
+
+	var item MagicItemEnriched
+	if err := json.NewDecoder(resp.Body).Decode(&item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
@@ -2,12 +2,15 @@ package backgroundModel
 
 import (
 	"encoding/json"
+	classModel "modules/dndcharactersheet/internal/class"
 	"os"
 )
 
 type Background struct {
-	Name               string   `json:"name"`
-	SkillProficiencies []string `json:"skill_proficiencies"`
+	Name               string                    `json:"name"`
+	SkillProficiencies []string                  `json:"skill_proficiencies"`
+	Equipment          []classModel.StartingItem `json:"equipment"`
+	Gold               int                       `json:"gold"` // Gold pieces in the starting pouch
 }
 
 func LoadBackgrounds(filename string) ([]Background, error) {
@@ -1,33 +1,138 @@
 package characterModel
 
 type Character struct {
-	Name               string      `json:"name"`
-	Race               string      `json:"race"`
-	Class              string      `json:"class"`
-	Level              int         `json:"level"`
-	Str                int         `json:"str"`
-	Dex                int         `json:"dex"`
-	Con                int         `json:"con"`
-	Int                int         `json:"int"`
-	Wis                int         `json:"wis"`
-	Cha                int         `json:"cha"`
-	Background         string      `json:"background"`
-	Proficiency        int         `json:"proficiency"`
-	SkillProficiencies []string    `json:"skill_proficiencies"`
-	MainHand           string      `json:"main_hand,omitempty"`
-	OffHand            string      `json:"off_hand,omitempty"`
-	Armor              string      `json:"armor,omitempty"`
-	Shield             string      `json:"shield,omitempty"`
-	Spellcasting       interface{} `json:"spellcasting"` // Spellcasting data handled in service logic
+	Name                string          `json:"name"`
+	Race                string          `json:"race"`
+	Class               string          `json:"class"`
+	Subclass            string          `json:"subclass,omitempty"` // Cleric domain, paladin oath, ...
+	Level               int             `json:"level"`
+	Str                 int             `json:"str"`
+	Dex                 int             `json:"dex"`
+	Con                 int             `json:"con"`
+	Int                 int             `json:"int"`
+	Wis                 int             `json:"wis"`
+	Cha                 int             `json:"cha"`
+	Background          string          `json:"background"`
+	Proficiency         int             `json:"proficiency"`
+	BaseSpeed           int             `json:"base_speed,omitempty"` // Racial walking speed in feet
+	SkillProficiencies  []string        `json:"skill_proficiencies"`
+	ArmorProficiencies  []string        `json:"armor_proficiencies,omitempty"`
+	WeaponProficiencies []string        `json:"weapon_proficiencies,omitempty"`
+	SavingThrows        []string        `json:"saving_throws,omitempty"` // Saving throw proficiencies, e.g. "str"
+	HitDie              int             `json:"hit_die,omitempty"`       // Class hit die size, e.g. 8 for d8
+	MaxHP               int             `json:"max_hp,omitempty"`
+	CurrentHP           int             `json:"current_hp"`
+	TempHP              int             `json:"temp_hp,omitempty"`
+	HitDice             int             `json:"hit_dice"` // Hit dice left to spend on short rests
+	Exhaustion          int             `json:"exhaustion,omitempty"`
+	Conditions          []string        `json:"conditions,omitempty"`    // SRD conditions like "poisoned"; exhaustion is tracked above
+	Concentration       string          `json:"concentration,omitempty"` // Spell the character is concentrating on
+	DeathSaves          DeathSaves      `json:"death_saves"`             // Counted while dying at 0 hit points
+	Stable              bool            `json:"stable,omitempty"`        // At 0 hit points but no longer making death saves
+	Dead                bool            `json:"dead,omitempty"`
+	Resources           []Resource      `json:"resources,omitempty"` // Limited-use class features
+	MainHand            string          `json:"main_hand,omitempty"`
+	OffHand             string          `json:"off_hand,omitempty"`
+	Armor               string          `json:"armor,omitempty"`
+	Shield              string          `json:"shield,omitempty"`
+	Inventory           []InventoryItem `json:"inventory,omitempty"`        // Unequipped gear
+	SpentAmmunition     map[string]int  `json:"spent_ammunition,omitempty"` // Ammunition fired since the last recovery
+	MagicItems          []MagicItem     `json:"magic_items,omitempty"`
+	Wallet              Wallet          `json:"wallet"`
+	Ledger              []Transaction   `json:"ledger,omitempty"` // Coin transactions for the DM to audit
+	Spellcasting        interface{}     `json:"spellcasting"`     // Spellcasting data handled in service logic
 	// Data for frontend display
-	StrMod            int `json:"str_mod"`
-	DexMod            int `json:"dex_mod"`
-	ConMod            int `json:"con_mod"`
-	IntMod            int `json:"int_mod"`
-	WisMod            int `json:"wis_mod"`
-	ChaMod            int `json:"cha_mod"`
-	ArmorClass        int `json:"armor_class"`
-	Initiative        int `json:"initiative"`
-	PassivePerception int `json:"passive_perception"`
-	SpellAttackBonus  int `json:"spell_attack_bonus,omitempty"`
+	StrMod              int            `json:"str_mod"`
+	DexMod              int            `json:"dex_mod"`
+	ConMod              int            `json:"con_mod"`
+	IntMod              int            `json:"int_mod"`
+	WisMod              int            `json:"wis_mod"`
+	ChaMod              int            `json:"cha_mod"`
+	ArmorClass          int            `json:"armor_class"`
+	Initiative          int            `json:"initiative"`
+	PassivePerception   int            `json:"passive_perception"`
+	CarriedWeight       float64        `json:"carried_weight,omitempty"`
+	Speed               int            `json:"speed,omitempty"`
+	ReducedMaxHP        int            `json:"reduced_max_hp,omitempty"` // Hit point maximum while exhaustion halves it
+	ConditionNotes      []string       `json:"condition_notes,omitempty"`
+	StealthDisadvantage bool           `json:"stealth_disadvantage,omitempty"`
+	SpellAttackBonus    int            `json:"spell_attack_bonus,omitempty"`
+	Saves               map[string]int `json:"saves,omitempty"` // Saving throw modifiers by ability, e.g. "str"
+	Attacks             []Attack       `json:"attacks,omitempty"`
+	SpellDamage         []SpellDamage  `json:"spell_damage,omitempty"`
+}
+
+// DeathSaves counts the death saving throws made while dying; three of either ends it
+type DeathSaves struct {
+	Successes int `json:"successes"`
+	Failures  int `json:"failures"`
+}
+
+// Resource is a limited-use class feature and the uses left until it recharges
+type Resource struct {
+	Name      string `json:"name"`
+	Max       int    `json:"max"`
+	Remaining int    `json:"remaining"`
+	Recharge  string `json:"recharge"` // "short" or "long" rest
+}
+
+// Attack is a weapon attack line as shown in the sheet's Attacks & Spellcasting section
+type Attack struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	AttackBonus int    `json:"attackBonus"`
+	Damage      string `json:"damage"`
+	Proficient  bool   `json:"proficient"`
+}
+
+// SpellDamage is one row of the sheet's spell damage table: a damaging spell cast at a slot level
+type SpellDamage struct {
+	Spell       string `json:"spell"`
+	Slot        int    `json:"slot"`             // 0 for cantrips
+	Damage      string `json:"damage"`           // e.g. "8d6 fire"
+	Attack      string `json:"attack,omitempty"` // "melee" or "ranged" for spell attacks
+	AttackBonus int    `json:"attackBonus,omitempty"`
+	Save        string `json:"save,omitempty"` // Ability of the target's saving throw, e.g. "dex"
+	SaveDC      int    `json:"saveDC,omitempty"`
+}
+
+// InventoryItem is a stack of unequipped gear from the SRD equipment catalog
+type InventoryItem struct {
+	Name     string `json:"name"`
+	Quantity int    `json:"quantity"`
+}
+
+// Wallet holds the character's coins
+type Wallet struct {
+	CP int `json:"cp"`
+	SP int `json:"sp"`
+	EP int `json:"ep"`
+	GP int `json:"gp"`
+	PP int `json:"pp"`
+}
+
+// Transaction is a ledger entry for coins spent or received
+type Transaction struct {
+	Time     string `json:"time"`
+	Type     string `json:"type"` // "buy", "sell", "deposit" or "withdraw"
+	Item     string `json:"item,omitempty"`
+	Quantity int    `json:"quantity,omitempty"`
+	Copper   int    `json:"copper"`  // Amount in copper pieces
+	Balance  int    `json:"balance"` // Wallet total in copper pieces after the transaction
+}
+
+// MagicItem is a magic item the character owns. Bonuses are copied from the catalog when the item is acquired.
+type MagicItem struct {
+	Name               string `json:"name"`
+	Type               string `json:"type"` // "weapon", "armor", "shield", "ring" or "wondrous"
+	Rarity             string `json:"rarity"`
+	RequiresAttunement bool   `json:"requires_attunement"`
+	Slot               string `json:"slot,omitempty"`     // Equipment slot the item is bound to, empty for worn items
+	BoundTo            string `json:"bound_to,omitempty"` // Weapon, armor or shield in the slot when the item was equipped
+	Equipped           bool   `json:"equipped"`
+	Attuned            bool   `json:"attuned"`
+	ACBonus            int    `json:"ac_bonus,omitempty"`
+	SaveBonus          int    `json:"save_bonus,omitempty"`
+	AttackBonus        int    `json:"attack_bonus,omitempty"`
+	DamageBonus        int    `json:"damage_bonus,omitempty"`
 }
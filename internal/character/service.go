@@ -3,6 +3,7 @@ package characterModel
 import (
 	backgroundModel "modules/dndcharactersheet/internal/background"
 	classModel "modules/dndcharactersheet/internal/class"
+	raceModel "modules/dndcharactersheet/internal/race"
 	"sort"
 	"strings"
 )
@@ -37,6 +38,26 @@ func (cs *CharacterService) AbilityModifier(score int) int {
 	return result
 }
 
+// AbilityScore returns the score for an ability given as "str" or "strength" (case-insensitive)
+func (cs *CharacterService) AbilityScore(character *Character, ability string) int {
+	switch strings.ToLower(strings.TrimSpace(ability)) {
+	case "str", "strength":
+		return character.Str
+	case "dex", "dexterity":
+		return character.Dex
+	case "con", "constitution":
+		return character.Con
+	case "int", "intelligence":
+		return character.Int
+	case "wis", "wisdom":
+		return character.Wis
+	case "cha", "charisma":
+		return character.Cha
+	default:
+		return 10
+	}
+}
+
 func (cs *CharacterService) ApplyRacialBonuses(character *Character) {
 	switch strings.ToLower(character.Race) {
 	case "dwarf":
@@ -116,3 +137,66 @@ func (cs *CharacterService) CombineSkillProficiencies(background backgroundModel
 
 	return combined
 }
+
+// CombineArmorProficiencies merges class and racial armor proficiencies (e.g. "light", "shields")
+func (cs *CharacterService) CombineArmorProficiencies(class classModel.Class, race raceModel.Race) []string {
+	return mergeProficiencies(class.ArmorProficiencies, race.ArmorProficiencies)
+}
+
+// CombineWeaponProficiencies merges class and racial weapon proficiencies.
+// Entries are either a weapon category ("simple", "martial") or a specific weapon name.
+func (cs *CharacterService) CombineWeaponProficiencies(class classModel.Class, race raceModel.Race) []string {
+	return mergeProficiencies(class.WeaponProficiencies, race.WeaponProficiencies)
+}
+
+// mergeProficiencies lowercases and de-duplicates proficiency lists, keeping their order
+func mergeProficiencies(lists ...[]string) []string {
+	seen := make(map[string]bool)
+	var combined []string
+	for _, list := range lists {
+		for _, p := range list {
+			p = strings.ToLower(strings.TrimSpace(p))
+			if p == "" || seen[p] {
+				continue
+			}
+			seen[p] = true
+			combined = append(combined, p)
+		}
+	}
+	return combined
+}
+
+// HitPointMaximum returns the SRD hit point maximum: the full hit die at 1st level and the
+// rounded-up average for each level after, plus the Constitution modifier per level (at least 1 per level)
+func (cs *CharacterService) HitPointMaximum(hitDie int, level int, con int) int {
+	if hitDie == 0 || level < 1 {
+		return 0
+	}
+	conMod := cs.AbilityModifier(con)
+	total := max(1, hitDie+conMod)
+	for l := 2; l <= level; l++ {
+		total += max(1, hitDie/2+1+conMod)
+	}
+	return total
+}
+
+// SyncResources sets the character's class resources to their maximums for its level,
+// keeping the uses already spent
+func (cs *CharacterService) SyncResources(character *Character, class classModel.Class) {
+	previous := map[string]Resource{}
+	for _, r := range character.Resources {
+		previous[strings.ToLower(r.Name)] = r
+	}
+	character.Resources = nil
+	for _, r := range class.Resources {
+		maxUses := r.MaxUses(character.Level, cs.AbilityModifier(cs.AbilityScore(character, r.Ability)))
+		if maxUses == 0 {
+			continue
+		}
+		res := Resource{Name: r.Name, Max: maxUses, Remaining: maxUses, Recharge: r.RechargeAt(character.Level)}
+		if prev, ok := previous[strings.ToLower(r.Name)]; ok {
+			res.Remaining = min(prev.Remaining, maxUses)
+		}
+		character.Resources = append(character.Resources, res)
+	}
+}
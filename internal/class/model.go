@@ -2,13 +2,122 @@ package classModel
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
+	"sort"
+	"strings"
 )
 
 type Class struct {
-	Name               string   `json:"name"`
-	SkillProficiencies []string `json:"skill_proficiencies"`
-	SkillCount         int      `json:"skill_count"` // How many skills they can choose
+	Name                string            `json:"name"`
+	SkillProficiencies  []string          `json:"skill_proficiencies"`
+	SkillCount          int               `json:"skill_count"` // How many skills they can choose
+	HitDie              int               `json:"hit_die"`     // Size of the hit die, e.g. 8 for d8
+	ArmorProficiencies  []string          `json:"armor_proficiencies"`
+	WeaponProficiencies []string          `json:"weapon_proficiencies"`
+	SavingThrows        []string          `json:"saving_throws"` // Abbreviated abilities, e.g. "str"
+	Resources           []ClassResource   `json:"resources"`
+	Subclasses          []Subclass        `json:"subclasses,omitempty"`
+	StartingGold        string            `json:"starting_gold"` // Dice rolled for gold instead of equipment, e.g. "5d4x10"
+	StartingEquipment   StartingEquipment `json:"starting_equipment"`
+}
+
+// Subclass is a class specialization like a cleric domain or a paladin oath
+type Subclass struct {
+	Name           string           `json:"name"`
+	Level          int              `json:"level"`                     // Class level at which it is chosen
+	AlwaysPrepared map[int][]string `json:"always_prepared,omitempty"` // Spells always prepared from each class level on
+}
+
+// FindSubclass returns the class's subclass with the given name, or nil
+func (c Class) FindSubclass(name string) *Subclass {
+	for i, s := range c.Subclasses {
+		if strings.EqualFold(s.Name, strings.TrimSpace(name)) {
+			return &c.Subclasses[i]
+		}
+	}
+	return nil
+}
+
+// ChooseSubclass returns the subclass a character of the level can take
+func (c Class) ChooseSubclass(name string, level int) (*Subclass, error) {
+	sub := c.FindSubclass(name)
+	if sub == nil {
+		var names []string
+		for _, s := range c.Subclasses {
+			names = append(names, s.Name)
+		}
+		if len(names) == 0 {
+			return nil, fmt.Errorf("no subclasses known for class %s", c.Name)
+		}
+		return nil, fmt.Errorf("unknown %s subclass %q (choose from %s)", c.Name, name, strings.Join(names, ", "))
+	}
+	if level < sub.Level {
+		return nil, fmt.Errorf("%s is chosen at %s level %d", sub.Name, c.Name, sub.Level)
+	}
+	return sub, nil
+}
+
+// AlwaysPreparedAt returns the spells the subclass always has prepared at a class level, lowest level first
+func (s Subclass) AlwaysPreparedAt(level int) []string {
+	levels := make([]int, 0, len(s.AlwaysPrepared))
+	for lvl := range s.AlwaysPrepared {
+		if lvl <= level {
+			levels = append(levels, lvl)
+		}
+	}
+	sort.Ints(levels)
+	var spells []string
+	for _, lvl := range levels {
+		spells = append(spells, s.AlwaysPrepared[lvl]...)
+	}
+	return spells
+}
+
+// ClassResource is a limited-use class feature like Rage or Ki that recharges on a rest
+type ClassResource struct {
+	Name           string `json:"name"`
+	Recharge       string `json:"recharge"`                   // "short" or "long"
+	Uses           []int  `json:"uses,omitempty"`             // Uses by class level, starting at level 1
+	Ability        string `json:"ability,omitempty"`          // Uses equal this ability's modifier (minimum 1)
+	Bonus          int    `json:"bonus,omitempty"`            // Added to the ability modifier
+	PerLevel       int    `json:"per_level,omitempty"`        // Pool of this many points per class level
+	ShortRestLevel int    `json:"short_rest_level,omitempty"` // Level from which it recharges on a short rest
+}
+
+// MaxUses returns how many uses the resource has at a class level
+func (r ClassResource) MaxUses(level int, abilityMod int) int {
+	switch {
+	case r.PerLevel > 0:
+		return r.PerLevel * level
+	case r.Ability != "":
+		return max(1, abilityMod+r.Bonus)
+	case level >= 1 && level <= len(r.Uses):
+		return r.Uses[level-1]
+	}
+	return 0
+}
+
+// RechargeAt returns the rest that recharges the resource at a class level, "short" or "long"
+func (r ClassResource) RechargeAt(level int) string {
+	if r.ShortRestLevel > 0 && level >= r.ShortRestLevel {
+		return "short"
+	}
+	return r.Recharge
+}
+
+// StartingItem is an item granted at character creation. Names starting with "any",
+// like "any martial melee weapon", are placeholders the player fills in.
+type StartingItem struct {
+	Name     string `json:"name"`
+	Quantity int    `json:"quantity,omitempty"` // Defaults to 1
+}
+
+// StartingEquipment is what every member of the class starts with plus the
+// "(a) or (b)" choices, each choice being a list of alternative bundles
+type StartingEquipment struct {
+	Items   []StartingItem     `json:"items"`
+	Choices [][][]StartingItem `json:"choices"`
 }
 
 func LoadClasses(filename string) ([]Class, error) {
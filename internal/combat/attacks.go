@@ -0,0 +1,121 @@
+package combat
+
+import (
+	"fmt"
+	characterModel "modules/dndcharactersheet/internal/character"
+	"modules/dndcharactersheet/internal/condition"
+	"modules/dndcharactersheet/internal/equipment"
+	"strings"
+)
+
+// CalculateWeaponAttack returns the attack line for a weapon held in the given slot ("main hand" or "off hand").
+// The proficiency bonus is only added when the character is proficient with the weapon.
+func CalculateWeaponAttack(char *characterModel.Character, service *characterModel.CharacterService, weaponName string, slot string) characterModel.Attack {
+	stats, _ := equipment.LookupWeapon(weaponName)
+	strMod := service.AbilityModifier(char.Str)
+	dexMod := service.AbilityModifier(char.Dex)
+
+	attackType := "melee"
+	abilityMod := strMod
+	if stats.Ranged {
+		attackType = "ranged"
+		abilityMod = dexMod
+	}
+	// Finesse weapons use the better of STR and DEX
+	if stats.HasProperty("finesse") && dexMod > strMod {
+		abilityMod = dexMod
+	}
+
+	proficient := equipment.IsWeaponProficient(char, weaponName)
+	attackBonus := abilityMod
+	if proficient {
+		attackBonus += char.Proficiency
+	}
+
+	// Off-hand attacks don't add a positive ability modifier to damage
+	damageMod := abilityMod
+	if slot == "off hand" && damageMod > 0 {
+		damageMod = 0
+	}
+
+	magicAttack, magicDamage := MagicAttackBonus(char, slot)
+	attackBonus += magicAttack
+	damageMod += magicDamage
+
+	return characterModel.Attack{
+		Name:        strings.ToLower(weaponName),
+		Type:        attackType,
+		AttackBonus: attackBonus,
+		Damage:      formatDamage(stats.Damage, damageMod, stats.DamageType),
+		Proficient:  proficient,
+	}
+}
+
+// CalculateAttacks returns the attack lines for the character's equipped weapons
+func CalculateAttacks(char *characterModel.Character, service *characterModel.CharacterService) []characterModel.Attack {
+	var attacks []characterModel.Attack
+	if char.MainHand != "" {
+		attacks = append(attacks, CalculateWeaponAttack(char, service, char.MainHand, "main hand"))
+	}
+	if char.OffHand != "" {
+		attacks = append(attacks, CalculateWeaponAttack(char, service, char.OffHand, "off hand"))
+	}
+	return attacks
+}
+
+// FormatAttacks returns a formatted string for a character's weapon attacks
+func FormatAttacks(attacks []characterModel.Attack) string {
+	if len(attacks) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("Attacks:\n")
+	for _, a := range attacks {
+		sb.WriteString(fmt.Sprintf("  %s (%s): %+d to hit, %s", a.Name, a.Type, a.AttackBonus, a.Damage))
+		if !a.Proficient {
+			sb.WriteString(" (not proficient)")
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// ArmorProficiencyNotes lists the SRD penalties for wearing armor or a shield without proficiency
+func ArmorProficiencyNotes(char *characterModel.Character) []string {
+	var notes []string
+	for _, item := range []string{char.Armor, char.Shield} {
+		if item == "" || equipment.IsArmorProficient(char, item) {
+			continue
+		}
+		notes = append(notes, fmt.Sprintf("not proficient with %s: disadvantage on STR and DEX checks, saves and attacks; can't cast spells", item))
+	}
+	return notes
+}
+
+// SpellcastingBlocked returns the reason the character can't cast spells, or "" if nothing blocks it
+func SpellcastingBlocked(char *characterModel.Character) string {
+	if reason := condition.Incapacitated(char); reason != "" {
+		return fmt.Sprintf("can't cast spells while %s", reason)
+	}
+	for _, item := range []string{char.Armor, char.Shield} {
+		if item != "" && !equipment.IsArmorProficient(char, item) {
+			return fmt.Sprintf("can't cast spells while wearing %s without proficiency", item)
+		}
+	}
+	return ""
+}
+
+// formatDamage joins damage dice, modifier and type, e.g. "1d8+3 slashing"
+func formatDamage(dice string, mod int, damageType string) string {
+	if dice == "" {
+		return "-"
+	}
+	damage := dice
+	if mod != 0 {
+		damage = fmt.Sprintf("%s%+d", dice, mod)
+	}
+	if damageType != "" {
+		damage += " " + damageType
+	}
+	return damage
+}
@@ -1,13 +1,21 @@
 package combat
 
 import (
-	"modules/dndcharactersheet/internal/api"
+	"fmt"
 	characterModel "modules/dndcharactersheet/internal/character"
+	"modules/dndcharactersheet/internal/condition"
+	"modules/dndcharactersheet/internal/equipment"
 	"strings"
 )
 
-// CalculateArmorClass returns the armor class for a character using real-time API enrichment.
+// CalculateArmorClass returns the armor class for a character using real-time API enrichment,
+// including bonuses from equipped and attuned magic items.
 func CalculateArmorClass(char *characterModel.Character, service *characterModel.CharacterService) int {
+	return baseArmorClass(char, service) + MagicACBonus(char)
+}
+
+// baseArmorClass returns the armor class from armor, shield and unarmored defense
+func baseArmorClass(char *characterModel.Character, service *characterModel.CharacterService) int {
 	// Barbarian Unarmored Defense: AC = 10 + Dex mod + Con mod (+ shield bonus if equipped) if no armor
 	if strings.ToLower(char.Class) == "barbarian" && char.Armor == "" {
 		ac := 10 + service.AbilityModifier(char.Dex) + service.AbilityModifier(char.Con)
@@ -24,60 +32,30 @@ func CalculateArmorClass(char *characterModel.Character, service *characterModel
 	baseAC := 10
 	dexMod := service.AbilityModifier(char.Dex)
 
-	// Get armor AC from API if equipped, fallback to hardcoded values if API fails
+	// Armor AC comes from API enrichment, falling back to the SRD armor table
 	if char.Armor != "" {
-		apiIndex := api.ToAPIIndex(char.Armor)
-		armor, err := api.GetArmor(apiIndex)
-		if err == nil && armor != nil {
-			baseAC = armor.ArmorClass.Base
-			if armor.ArmorClass.DexBonus {
-				baseAC += dexMod
+		armor, found := equipment.LookupArmor(char.Armor)
+		if found {
+			baseAC = armor.Base
+			if armor.DexBonus {
+				if armor.MaxBonus > 0 {
+					baseAC += min(dexMod, armor.MaxBonus)
+				} else {
+					baseAC += dexMod
+				}
 			}
 		} else {
-			// Fallback: hardcoded AC values for common armor
-			switch apiIndex {
-			case "padded":
-				baseAC = 11 + dexMod
-			case "leather-armor":
-				baseAC = 11 + dexMod
-			case "studded-leather":
-				baseAC = 12 + dexMod
-			case "hide-armor":
-				baseAC = 12 + min(dexMod, 2)
-			case "chain-shirt":
-				baseAC = 13 + min(dexMod, 2)
-			case "scale-mail":
-				baseAC = 14 + min(dexMod, 2)
-			case "breastplate":
-				baseAC = 14 + min(dexMod, 2)
-			case "half-plate":
-				baseAC = 15 + min(dexMod, 2)
-			case "ring-mail":
-				baseAC = 14
-			case "chain-mail":
-				baseAC = 16
-			case "splint":
-				baseAC = 17
-			case "plate-armor":
-				baseAC = 18
-			default:
-				baseAC += dexMod
-			}
+			baseAC += dexMod
 		}
 	} else {
 		baseAC += dexMod
 	}
 
-	// Add shield bonus if equipped (assume +2 for D&D 5e shields)
+	// Add shield bonus if equipped (+2 for D&D 5e shields)
 	if char.Shield != "" {
-		shield, err := api.GetArmor(char.Shield)
-		if err == nil && shield != nil {
-			// If shield AC is in API, use it, else default to +2
-			if shield.ArmorClass.Base > 2 {
-				baseAC += shield.ArmorClass.Base
-			} else {
-				baseAC += 2
-			}
+		shield, found := equipment.LookupArmor(char.Shield)
+		if found && shield.Base > 0 {
+			baseAC += shield.Base
 		} else {
 			baseAC += 2
 		}
@@ -85,11 +63,201 @@ func CalculateArmorClass(char *characterModel.Character, service *characterModel
 	return baseAC
 }
 
+// CalculateSpeed returns the walking speed after armor penalties, encumbrance and conditions.
+// Heavy armor with a Strength requirement above the character's STR score costs 10 feet, except for dwarves.
+func CalculateSpeed(char *characterModel.Character) int {
+	speed, _ := condition.Speed(char, unhinderedSpeed(char))
+	return speed
+}
+
+// unhinderedSpeed returns the walking speed before conditions
+func unhinderedSpeed(char *characterModel.Character) int {
+	speed := char.BaseSpeed
+	if speed == 0 {
+		speed = 30
+	}
+	if penalty := ArmorSpeedPenalty(char); penalty != "" {
+		speed -= 10
+	}
+	switch Encumbrance(char) {
+	case EncumbranceHeavy, EncumbranceOverCapacity:
+		speed -= 20
+	case EncumbranceLight:
+		speed -= 10
+	}
+	if speed < 0 {
+		speed = 0
+	}
+	return speed
+}
+
+// Variant encumbrance levels from the SRD
+const (
+	EncumbranceNone         = ""
+	EncumbranceLight        = "encumbered"
+	EncumbranceHeavy        = "heavily encumbered"
+	EncumbranceOverCapacity = "over carrying capacity"
+)
+
+// Encumbrance returns the variant encumbrance level for the character's carried weight:
+// over 5x STR is encumbered, over 10x STR heavily encumbered and over 15x STR beyond carrying capacity,
+// which is also heavily encumbered.
+func Encumbrance(char *characterModel.Character) string {
+	str := float64(char.Str)
+	switch {
+	case char.CarriedWeight > 15*str:
+		return EncumbranceOverCapacity
+	case char.CarriedWeight > 10*str:
+		return EncumbranceHeavy
+	case char.CarriedWeight > 5*str:
+		return EncumbranceLight
+	default:
+		return EncumbranceNone
+	}
+}
+
+// EncumbranceNotes describes the mechanical effects of the character's encumbrance level
+func EncumbranceNotes(char *characterModel.Character) []string {
+	switch Encumbrance(char) {
+	case EncumbranceOverCapacity:
+		return []string{
+			"heavily encumbered: speed -20 ft, disadvantage on STR, DEX and CON checks, saves and attacks",
+			fmt.Sprintf("over carrying capacity: carrying %s lb of %d lb, the rest can only be pushed, dragged or lifted",
+				equipment.FormatWeight(char.CarriedWeight), 15*char.Str),
+		}
+	case EncumbranceHeavy:
+		return []string{"heavily encumbered: speed -20 ft, disadvantage on STR, DEX and CON checks, saves and attacks"}
+	case EncumbranceLight:
+		return []string{"encumbered: speed -10 ft"}
+	default:
+		return nil
+	}
+}
+
+// ArmorSpeedPenalty describes the armor Strength requirement the character fails to meet, or "" if none
+func ArmorSpeedPenalty(char *characterModel.Character) string {
+	if char.Armor == "" || strings.Contains(strings.ToLower(char.Race), "dwarf") {
+		return ""
+	}
+	armor, found := equipment.LookupArmor(char.Armor)
+	if !found || armor.StrMinimum == 0 || char.Str >= armor.StrMinimum {
+		return ""
+	}
+	return fmt.Sprintf("%s requires STR %d", char.Armor, armor.StrMinimum)
+}
+
+// HasStealthDisadvantage reports whether the equipped armor imposes disadvantage on Stealth checks
+func HasStealthDisadvantage(char *characterModel.Character) bool {
+	if char.Armor == "" {
+		return false
+	}
+	armor, found := equipment.LookupArmor(char.Armor)
+	return found && armor.StealthDisadvantage
+}
+
 // CalculateInitiative returns the initiative bonus for a character.
 func CalculateInitiative(char *characterModel.Character, service *characterModel.CharacterService) int {
 	return service.AbilityModifier(char.Dex)
 }
 
+// CalculateSavingThrow returns the saving throw modifier for an ability ("str", "dex", ...),
+// adding proficiency for the class's saving throws and bonuses from magic items.
+func CalculateSavingThrow(char *characterModel.Character, service *characterModel.CharacterService, ability string) int {
+	mod := service.AbilityModifier(service.AbilityScore(char, ability))
+	if HasSaveProficiency(char, ability) {
+		mod += char.Proficiency
+	}
+	return mod + MagicSaveBonus(char)
+}
+
+// Abilities lists the six ability abbreviations in sheet order
+var Abilities = []string{"str", "dex", "con", "int", "wis", "cha"}
+
+// CalculateSavingThrows returns the saving throw modifier for every ability
+func CalculateSavingThrows(char *characterModel.Character, service *characterModel.CharacterService) map[string]int {
+	saves := make(map[string]int, len(Abilities))
+	for _, ability := range Abilities {
+		saves[ability] = CalculateSavingThrow(char, service, ability)
+	}
+	return saves
+}
+
+// FormatSavingThrows returns a formatted string for the saving throw modifiers, marking proficient saves
+func FormatSavingThrows(char *characterModel.Character, service *characterModel.CharacterService) string {
+	var parts []string
+	for _, ability := range Abilities {
+		part := fmt.Sprintf("%s %+d", strings.ToUpper(ability), CalculateSavingThrow(char, service, ability))
+		if HasSaveProficiency(char, ability) {
+			part += "*"
+		}
+		parts = append(parts, part)
+	}
+	return fmt.Sprintf("Saving throws: %s\n", strings.Join(parts, ", "))
+}
+
+// HasSaveProficiency reports whether the character is proficient in saving throws for the ability
+func HasSaveProficiency(char *characterModel.Character, ability string) bool {
+	ability = strings.ToLower(strings.TrimSpace(ability))
+	if len(ability) > 3 {
+		ability = ability[:3]
+	}
+	for _, save := range char.SavingThrows {
+		if strings.EqualFold(save, ability) {
+			return true
+		}
+	}
+	return false
+}
+
+// Skills maps each skill to the ability it uses
+var Skills = map[string]string{
+	"acrobatics":      "dex",
+	"animal handling": "wis",
+	"arcana":          "int",
+	"athletics":       "str",
+	"deception":       "cha",
+	"history":         "int",
+	"insight":         "wis",
+	"intimidation":    "cha",
+	"investigation":   "int",
+	"medicine":        "wis",
+	"nature":          "int",
+	"perception":      "wis",
+	"performance":     "cha",
+	"persuasion":      "cha",
+	"religion":        "int",
+	"sleight of hand": "dex",
+	"stealth":         "dex",
+	"survival":        "wis",
+}
+
+// SkillAbility returns the ability a skill uses, accepting "sleight-of-hand" for "sleight of hand"
+func SkillAbility(skill string) (string, string, bool) {
+	skill = strings.ToLower(strings.TrimSpace(strings.ReplaceAll(skill, "-", " ")))
+	ability, ok := Skills[skill]
+	return skill, ability, ok
+}
+
+// HasSkillProficiency reports whether the character is proficient in the skill
+func HasSkillProficiency(char *characterModel.Character, skill string) bool {
+	for _, s := range char.SkillProficiencies {
+		if strings.EqualFold(s, skill) {
+			return true
+		}
+	}
+	return false
+}
+
+// CalculateSkill returns the modifier for a skill, adding proficiency when the character is proficient
+func CalculateSkill(char *characterModel.Character, service *characterModel.CharacterService, skill string) int {
+	skill, ability, _ := SkillAbility(skill)
+	mod := service.AbilityModifier(service.AbilityScore(char, ability))
+	if HasSkillProficiency(char, skill) {
+		mod += char.Proficiency
+	}
+	return mod
+}
+
 // CalculatePassivePerception returns the passive perception for a character.
 func CalculatePassivePerception(char *characterModel.Character, service *characterModel.CharacterService) int {
 	base := 10 + service.AbilityModifier(char.Wis)
@@ -0,0 +1,68 @@
+package combat
+
+import (
+	characterModel "modules/dndcharactersheet/internal/character"
+	"modules/dndcharactersheet/internal/dice"
+	"testing"
+)
+
+func TestEncumberedSpeed(t *testing.T) {
+	tests := []struct {
+		carried float64
+		level   string
+		speed   int
+	}{
+		{50, EncumbranceNone, 30},
+		{51, EncumbranceLight, 20},
+		{101, EncumbranceHeavy, 10},
+		{151, EncumbranceOverCapacity, 10},
+	}
+	for _, tt := range tests {
+		char := &characterModel.Character{Str: 10, BaseSpeed: 30, CarriedWeight: tt.carried}
+		if level := Encumbrance(char); level != tt.level {
+			t.Errorf("Encumbrance at %v lb = %q, want %q", tt.carried, level, tt.level)
+		}
+		if speed := CalculateSpeed(char); speed != tt.speed {
+			t.Errorf("CalculateSpeed at %v lb = %d, want %d", tt.carried, speed, tt.speed)
+		}
+	}
+}
+
+func TestEncumberedRolls(t *testing.T) {
+	service := characterModel.NewCharacterService()
+	tests := []struct {
+		carried      float64
+		disadvantage bool
+	}{
+		{100, false}, // encumbered only slows
+		{101, true},
+		{151, true},
+	}
+	for _, tt := range tests {
+		char := &characterModel.Character{Str: 10, Dex: 10, Con: 10, Wis: 10, CarriedWeight: tt.carried}
+		check, err := RollCheck(char, service, "athletics", false, false, dice.Faces(12, 7))
+		if err != nil {
+			t.Fatal(err)
+		}
+		save, err := RollSave(char, service, "con", false, false, dice.Faces(12, 7))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, roll := range []D20Roll{check, save} {
+			got := len(roll.Disadvantage) > 0 && roll.Disadvantage[0] == EncumbranceHeavy
+			if got != tt.disadvantage {
+				t.Errorf("%s carrying %v lb: disadvantage %v, want %v", roll.Label, tt.carried, roll.Disadvantage, tt.disadvantage)
+			}
+		}
+		wis, err := RollSave(char, service, "wis", false, false, dice.Faces(12))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(wis.Disadvantage) > 0 {
+			t.Errorf("WIS save carrying %v lb has disadvantage %v", tt.carried, wis.Disadvantage)
+		}
+		if got := encumbranceDisadvantage(char, "dex"); (len(got) > 0) != tt.disadvantage {
+			t.Errorf("DEX rolls carrying %v lb: disadvantage %v, want %v", tt.carried, got, tt.disadvantage)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package combat
+
+import (
+	"fmt"
+	characterModel "modules/dndcharactersheet/internal/character"
+	"modules/dndcharactersheet/internal/condition"
+)
+
+// DamageResult describes what damage did to a character
+type DamageResult struct {
+	Absorbed        int // Taken by temporary hit points
+	Lost            int // Taken from hit points
+	HitPointsNow    int
+	ConcentrationDC int    // DC of the Constitution save to keep concentrating, 0 when not concentrating
+	Dropped         string // Concentration spell ended because the character fell to 0 hit points
+	Dying           bool   // Fell to 0 hit points and started making death saves
+	FailedSaves     int    // Death save failures from damage taken while at 0 hit points
+	Died            bool
+	MassiveDamage   bool // Died because the damage left over at 0 hit points reached the hit point maximum
+}
+
+// ApplyDamage takes damage from temporary hit points first, then hit points, down to 0.
+// A concentrating character that stays up has to make a Constitution save (see ConcentrationDC);
+// one that drops to 0 hit points loses concentration and starts dying, unless the damage left over
+// reaches the hit point maximum, halved from exhaustion level 4, which kills outright. Damage taken
+// at 0 hit points counts as a failed death save, two for a critical hit.
+func ApplyDamage(char *characterModel.Character, amount int, critical bool) (DamageResult, error) {
+	var result DamageResult
+	if amount < 0 {
+		return result, fmt.Errorf("damage can't be negative")
+	}
+	if char.Dead {
+		return result, fmt.Errorf("%s is dead", char.Name)
+	}
+	wasDown := char.CurrentHP == 0 && char.MaxHP > 0
+	result.Absorbed = min(amount, char.TempHP)
+	char.TempHP -= result.Absorbed
+	result.Lost = min(amount-result.Absorbed, char.CurrentHP)
+	char.CurrentHP -= result.Lost
+	result.HitPointsNow = char.CurrentHP
+	leftOver := amount - result.Absorbed - result.Lost
+
+	if char.Concentration != "" && amount > 0 {
+		if char.CurrentHP == 0 {
+			result.Dropped = char.Concentration
+			char.Concentration = ""
+		} else {
+			result.ConcentrationDC = ConcentrationDC(amount)
+		}
+	}
+
+	switch {
+	case char.CurrentHP > 0 || char.MaxHP == 0 || amount == result.Absorbed:
+	case leftOver >= condition.MaxHP(char):
+		result.Died, result.MassiveDamage = true, true
+		die(char)
+	case wasDown:
+		result.FailedSaves = 1
+		if critical {
+			result.FailedSaves = 2
+		}
+		char.Stable = false
+		char.DeathSaves.Failures = min(3, char.DeathSaves.Failures+result.FailedSaves)
+		if char.DeathSaves.Failures == 3 {
+			result.Died = true
+			die(char)
+		}
+	default:
+		result.Dying = true
+		fallUnconscious(char)
+	}
+	return result, nil
+}
+
+// ConcentrationDC returns the Constitution save DC to keep concentrating after taking damage:
+// 10 or half the damage, whichever is higher
+func ConcentrationDC(damage int) int {
+	return max(10, damage/2)
+}
+
+// ConcentrationSave resolves the Constitution save against the DC given the save total
+// (d20 roll plus modifier) and ends concentration when it fails. It reports whether the save held.
+func ConcentrationSave(char *characterModel.Character, total int, dc int) bool {
+	if total >= dc {
+		return true
+	}
+	char.Concentration = ""
+	return false
+}
@@ -0,0 +1,118 @@
+package combat
+
+import (
+	"fmt"
+	characterModel "modules/dndcharactersheet/internal/character"
+	"modules/dndcharactersheet/internal/condition"
+	"modules/dndcharactersheet/internal/dice"
+)
+
+// DeathSaveResult describes a death saving throw and where it left the character
+type DeathSaveResult struct {
+	Roll    D20Roll
+	Revived bool // Natural 20: back up with 1 hit point
+	Stable  bool // Third success
+	Died    bool // Third failure
+}
+
+// Dying reports whether the character is at 0 hit points and making death saves
+func Dying(char *characterModel.Character) bool {
+	return char.MaxHP > 0 && char.CurrentHP == 0 && !char.Stable && !char.Dead
+}
+
+// RollDeathSave rolls a death saving throw: 10 or higher is a success and lower a failure, a
+// natural 1 counts as two failures and a natural 20 brings the character back with 1 hit point.
+// Three successes make the character stable, three failures kill them.
+func RollDeathSave(char *characterModel.Character, rng dice.Source) (DeathSaveResult, error) {
+	var result DeathSaveResult
+	if !Dying(char) {
+		return result, fmt.Errorf("%s isn't dying", char.Name)
+	}
+	// death saves aren't tied to an ability but are saving throws, so exhaustion still counts
+	disadvantage, _ := condition.SaveModes(char, "")
+	roll, err := rollD20("Death save", 0, nil, disadvantage, rng)
+	if err != nil {
+		return result, err
+	}
+	result.Roll = roll
+
+	switch natural := roll.Natural(); {
+	case natural == 20:
+		char.CurrentHP = 1
+		Revive(char)
+		result.Revived = true
+	case natural == 1:
+		char.DeathSaves.Failures = min(3, char.DeathSaves.Failures+2)
+	case natural >= 10:
+		char.DeathSaves.Successes++
+	default:
+		char.DeathSaves.Failures++
+	}
+	switch {
+	case char.DeathSaves.Failures >= 3:
+		result.Died = true
+		die(char)
+	case char.DeathSaves.Successes >= 3:
+		result.Stable = true
+		char.Stable = true
+		char.DeathSaves = characterModel.DeathSaves{}
+	}
+	return result, nil
+}
+
+// Heal restores hit points up to the maximum, bringing a dying or stable character back up.
+// It returns the hit points regained.
+func Heal(char *characterModel.Character, amount int) (int, error) {
+	if amount <= 0 {
+		return 0, fmt.Errorf("healing must be positive")
+	}
+	if char.Dead {
+		return 0, fmt.Errorf("%s is dead and can't be healed", char.Name)
+	}
+	before := char.CurrentHP
+	char.CurrentHP = min(condition.MaxHP(char), char.CurrentHP+amount)
+	Revive(char)
+	return char.CurrentHP - before, nil
+}
+
+// Revive clears the dying state of a character back above 0 hit points: death saves reset and
+// they wake up
+func Revive(char *characterModel.Character) {
+	if char.CurrentHP == 0 || char.Dead {
+		return
+	}
+	// regaining hit points wakes the character up
+	condition.Remove(char, "unconscious", 0)
+	char.Stable = false
+	char.DeathSaves = characterModel.DeathSaves{}
+}
+
+// FormatDeathState describes a character who is dying, stable or dead, or returns ""
+func FormatDeathState(char *characterModel.Character) string {
+	switch {
+	case char.Dead:
+		return "dead"
+	case char.Stable && char.CurrentHP == 0:
+		return "stable at 0 hit points, unconscious"
+	case Dying(char):
+		return fmt.Sprintf("dying, death saves: %d successes, %d failures", char.DeathSaves.Successes, char.DeathSaves.Failures)
+	}
+	return ""
+}
+
+// fallUnconscious starts the dying state at 0 hit points
+func fallUnconscious(char *characterModel.Character) {
+	char.Stable = false
+	char.DeathSaves = characterModel.DeathSaves{}
+	if !condition.Has(char, "unconscious") {
+		condition.Add(char, "unconscious", 0)
+	}
+}
+
+// die marks the character dead
+func die(char *characterModel.Character) {
+	char.Dead = true
+	char.Stable = false
+	char.CurrentHP = 0
+	char.Concentration = ""
+}
@@ -0,0 +1,196 @@
+package combat
+
+import (
+	characterModel "modules/dndcharactersheet/internal/character"
+	"modules/dndcharactersheet/internal/condition"
+	"modules/dndcharactersheet/internal/dice"
+	"testing"
+)
+
+// hero returns a character with 20 maximum hit points at the given hit points
+func hero(hp int) *characterModel.Character {
+	return &characterModel.Character{Name: "Lidda", MaxHP: 20, CurrentHP: hp}
+}
+
+// dying returns a character at 0 hit points with the given death saves
+func dying(successes int, failures int) *characterModel.Character {
+	char := hero(0)
+	char.Conditions = []string{"unconscious"}
+	char.DeathSaves = characterModel.DeathSaves{Successes: successes, Failures: failures}
+	return char
+}
+
+// exhausted returns a character at exhaustion 4, halving the 20 hit point maximum to 10
+func exhausted(hp int) *characterModel.Character {
+	char := hero(hp)
+	char.Exhaustion = 4
+	return char
+}
+
+func TestApplyDamage(t *testing.T) {
+	tests := []struct {
+		name     string
+		char     *characterModel.Character
+		amount   int
+		critical bool
+		hp       int
+		failures int
+		dying    bool
+		died     bool
+		massive  bool
+	}{
+		{"stays up", hero(20), 19, false, 1, 0, false, false, false},
+		{"drops to 0", hero(5), 12, false, 0, 0, true, false, false},
+		{"massive damage", hero(5), 25, false, 0, 0, false, true, true},
+		{"one short of massive damage", hero(5), 24, false, 0, 0, true, false, false},
+		{"hit while dying", dying(1, 0), 3, false, 0, 1, false, false, false},
+		{"crit while dying", dying(0, 0), 3, true, 0, 2, false, false, false},
+		{"crit kills at one failure", dying(0, 1), 3, true, 0, 3, false, true, false},
+		{"third failure", dying(2, 2), 1, false, 0, 3, false, true, false},
+		{"massive damage while dying", dying(0, 0), 20, false, 0, 0, false, true, true},
+		{"massive damage at the halved maximum", exhausted(5), 15, false, 0, 0, false, true, true},
+		{"one short of the halved maximum", exhausted(5), 14, false, 0, 0, true, false, false},
+	}
+	for _, tt := range tests {
+		result, err := ApplyDamage(tt.char, tt.amount, tt.critical)
+		if err != nil {
+			t.Errorf("%s: ApplyDamage failed: %v", tt.name, err)
+			continue
+		}
+		if tt.char.CurrentHP != tt.hp || result.HitPointsNow != tt.hp {
+			t.Errorf("%s: %d hit points left, want %d", tt.name, tt.char.CurrentHP, tt.hp)
+		}
+		if result.Dying != tt.dying || result.Died != tt.died || result.MassiveDamage != tt.massive {
+			t.Errorf("%s: dying %v, died %v, massive %v; want %v, %v, %v", tt.name, result.Dying, result.Died, result.MassiveDamage, tt.dying, tt.died, tt.massive)
+		}
+		if tt.char.Dead != tt.died {
+			t.Errorf("%s: Dead = %v, want %v", tt.name, tt.char.Dead, tt.died)
+		}
+		if !tt.died && tt.char.DeathSaves.Failures != tt.failures {
+			t.Errorf("%s: %d death save failures, want %d", tt.name, tt.char.DeathSaves.Failures, tt.failures)
+		}
+		if tt.dying && !condition.Has(tt.char, "unconscious") {
+			t.Errorf("%s: not unconscious at 0 hit points", tt.name)
+		}
+	}
+}
+
+func TestApplyDamageTemporaryHitPoints(t *testing.T) {
+	char := hero(10)
+	char.TempHP = 5
+	char.Concentration = "Bless"
+	result, err := ApplyDamage(char, 8, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Absorbed != 5 || result.Lost != 3 || char.TempHP != 0 || char.CurrentHP != 7 {
+		t.Errorf("ApplyDamage(8) with 5 temporary hit points = %+v, left %d (+%d)", result, char.CurrentHP, char.TempHP)
+	}
+	if result.ConcentrationDC != 10 {
+		t.Errorf("ConcentrationDC = %d, want 10", result.ConcentrationDC)
+	}
+	if _, err := ApplyDamage(&characterModel.Character{Name: "Gone", Dead: true}, 1, false); err == nil {
+		t.Errorf("ApplyDamage to a dead character succeeded")
+	}
+}
+
+func TestRollDeathSave(t *testing.T) {
+	tests := []struct {
+		name      string
+		char      *characterModel.Character
+		face      int
+		successes int
+		failures  int
+		revived   bool
+		stable    bool
+		died      bool
+	}{
+		{"success", dying(0, 0), 10, 1, 0, false, false, false},
+		{"failure", dying(0, 0), 9, 0, 1, false, false, false},
+		{"natural 1 is two failures", dying(0, 0), 1, 0, 2, false, false, false},
+		{"natural 20 revives", dying(2, 2), 20, 0, 0, true, false, false},
+		{"third success stabilizes", dying(2, 1), 15, 0, 0, false, true, false},
+		{"third failure", dying(2, 2), 5, 2, 3, false, false, true},
+		{"natural 1 at one failure", dying(0, 1), 1, 0, 3, false, false, true},
+	}
+	for _, tt := range tests {
+		result, err := RollDeathSave(tt.char, dice.Faces(tt.face))
+		if err != nil {
+			t.Errorf("%s: RollDeathSave failed: %v", tt.name, err)
+			continue
+		}
+		if result.Revived != tt.revived || result.Stable != tt.stable || result.Died != tt.died {
+			t.Errorf("%s: revived %v, stable %v, died %v; want %v, %v, %v", tt.name, result.Revived, result.Stable, result.Died, tt.revived, tt.stable, tt.died)
+		}
+		if got := tt.char.DeathSaves; got.Successes != tt.successes || got.Failures != tt.failures {
+			t.Errorf("%s: death saves %+v, want %d successes and %d failures", tt.name, got, tt.successes, tt.failures)
+		}
+		if tt.char.Dead != tt.died || tt.char.Stable != tt.stable {
+			t.Errorf("%s: Dead = %v, Stable = %v", tt.name, tt.char.Dead, tt.char.Stable)
+		}
+		wantHP := 0
+		if tt.revived {
+			wantHP = 1
+		}
+		if tt.char.CurrentHP != wantHP {
+			t.Errorf("%s: %d hit points, want %d", tt.name, tt.char.CurrentHP, wantHP)
+		}
+		if tt.revived && condition.Has(tt.char, "unconscious") {
+			t.Errorf("%s: still unconscious", tt.name)
+		}
+	}
+}
+
+func TestRollDeathSaveNotDying(t *testing.T) {
+	stable := dying(0, 0)
+	stable.Stable = true
+	for _, char := range []*characterModel.Character{hero(5), stable, {Name: "Gone", MaxHP: 20, Dead: true}} {
+		if _, err := RollDeathSave(char, dice.Faces(10)); err == nil {
+			t.Errorf("RollDeathSave succeeded for %+v", char)
+		}
+	}
+}
+
+func TestHeal(t *testing.T) {
+	tests := []struct {
+		name   string
+		char   *characterModel.Character
+		amount int
+		healed int
+		hp     int
+	}{
+		{"wounded", hero(5), 8, 8, 13},
+		{"up to the maximum", hero(15), 10, 5, 20},
+		{"dying", dying(2, 2), 4, 4, 4},
+	}
+	for _, tt := range tests {
+		healed, err := Heal(tt.char, tt.amount)
+		if err != nil {
+			t.Errorf("%s: Heal failed: %v", tt.name, err)
+			continue
+		}
+		if healed != tt.healed || tt.char.CurrentHP != tt.hp {
+			t.Errorf("%s: healed %d to %d hit points, want %d to %d", tt.name, healed, tt.char.CurrentHP, tt.healed, tt.hp)
+		}
+		if tt.char.DeathSaves != (characterModel.DeathSaves{}) || tt.char.Stable || condition.Has(tt.char, "unconscious") {
+			t.Errorf("%s: healing left death saves %+v, stable %v, conditions %v", tt.name, tt.char.DeathSaves, tt.char.Stable, tt.char.Conditions)
+		}
+	}
+
+	stable := dying(0, 0)
+	stable.Stable = true
+	if _, err := Heal(stable, 1); err != nil || stable.Stable || Dying(stable) {
+		t.Errorf("healing a stable character left %+v, %v", stable, err)
+	}
+	exhausted := hero(5)
+	exhausted.Exhaustion = 4
+	if healed, _ := Heal(exhausted, 20); healed != 5 || exhausted.CurrentHP != 10 {
+		t.Errorf("healing at exhaustion 4 healed %d to %d, want 5 to the halved maximum 10", healed, exhausted.CurrentHP)
+	}
+	if _, err := Heal(&characterModel.Character{Name: "Gone", MaxHP: 20, Dead: true}, 5); err == nil {
+		t.Errorf("healing a dead character succeeded")
+	}
+	if _, err := Heal(hero(5), 0); err == nil {
+		t.Errorf("healing 0 succeeded")
+	}
+}
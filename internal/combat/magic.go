@@ -0,0 +1,74 @@
+package combat
+
+import (
+	characterModel "modules/dndcharactersheet/internal/character"
+	"modules/dndcharactersheet/internal/magicitem"
+	"strings"
+)
+
+// boundItemEquipped reports whether the slot a magic item is bound to still holds the item it was
+// equipped with, so a +1 longsword's bonus doesn't move to a dagger swapped into the same hand
+func boundItemEquipped(char *characterModel.Character, item characterModel.MagicItem) bool {
+	held := ""
+	switch item.Slot {
+	case "":
+		return true
+	case "main hand":
+		held = char.MainHand
+	case "off hand":
+		held = char.OffHand
+	case "armor":
+		held = char.Armor
+	case "shield":
+		held = char.Shield
+	default:
+		return false
+	}
+	// items equipped before the bound item was recorded only need the slot filled
+	if item.BoundTo == "" {
+		return held != ""
+	}
+	return strings.EqualFold(held, item.BoundTo)
+}
+
+// activeMagicItems returns the magic items whose bonuses currently apply
+func activeMagicItems(char *characterModel.Character) []characterModel.MagicItem {
+	var active []characterModel.MagicItem
+	for _, it := range char.MagicItems {
+		if magicitem.IsActive(it) && boundItemEquipped(char, it) {
+			active = append(active, it)
+		}
+	}
+	return active
+}
+
+// MagicACBonus returns the total AC bonus from equipped and attuned magic items
+func MagicACBonus(char *characterModel.Character) int {
+	bonus := 0
+	for _, it := range activeMagicItems(char) {
+		bonus += it.ACBonus
+	}
+	return bonus
+}
+
+// MagicSaveBonus returns the total saving throw bonus from equipped and attuned magic items
+func MagicSaveBonus(char *characterModel.Character) int {
+	bonus := 0
+	for _, it := range activeMagicItems(char) {
+		bonus += it.SaveBonus
+	}
+	return bonus
+}
+
+// MagicAttackBonus returns the attack and damage bonus for attacks with the weapon in the given slot.
+// Magic weapons only boost their own slot; worn items like gloves boost every attack.
+func MagicAttackBonus(char *characterModel.Character, slot string) (int, int) {
+	attack, damage := 0, 0
+	for _, it := range activeMagicItems(char) {
+		if it.Slot == "" || it.Slot == slot {
+			attack += it.AttackBonus
+			damage += it.DamageBonus
+		}
+	}
+	return attack, damage
+}
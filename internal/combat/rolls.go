@@ -0,0 +1,280 @@
+package combat
+
+import (
+	"fmt"
+	characterModel "modules/dndcharactersheet/internal/character"
+	"modules/dndcharactersheet/internal/condition"
+	"modules/dndcharactersheet/internal/dice"
+	"modules/dndcharactersheet/internal/equipment"
+	"strings"
+)
+
+// D20Roll is a rolled ability check, saving throw or attack roll
+type D20Roll struct {
+	Label        string // e.g. "Stealth check"
+	Modifier     int
+	Advantage    []string // Why the roll has advantage
+	Disadvantage []string // Why the roll has disadvantage
+	AutoFail     []string // Conditions that make a saving throw fail whatever the roll
+	Result       dice.Result
+}
+
+// Natural returns the d20 face that counted
+func (r D20Roll) Natural() int {
+	return r.Result.Natural()
+}
+
+// Total returns the d20 plus the modifier
+func (r D20Roll) Total() int {
+	return r.Result.Total
+}
+
+// AttackRoll is a rolled weapon attack and, when it hits, its damage
+type AttackRoll struct {
+	D20Roll
+	Attack     characterModel.Attack
+	TargetAC   int  // 0 when the target's AC wasn't given
+	Hit        bool // Always true for a natural 20 and false for a natural 1
+	Critical   bool
+	Damage     *dice.Result // nil on a miss
+	DamageType string
+}
+
+// rollD20 rolls a d20 plus the modifier, with advantage and disadvantage for the given reasons
+func rollD20(label string, mod int, advantage []string, disadvantage []string, rng dice.Source) (D20Roll, error) {
+	expr := "1d20"
+	if mod != 0 {
+		expr += fmt.Sprintf("%+d", mod)
+	}
+	if len(advantage) > 0 {
+		expr += " adv"
+	}
+	if len(disadvantage) > 0 {
+		expr += " dis"
+	}
+	result, err := dice.Roll(expr, rng)
+	if err != nil {
+		return D20Roll{}, err
+	}
+	return D20Roll{Label: label, Modifier: mod, Advantage: advantage, Disadvantage: disadvantage, Result: result}, nil
+}
+
+// armorDisadvantage returns why the character has disadvantage on STR and DEX rolls from armor
+// or a shield worn without proficiency
+func armorDisadvantage(char *characterModel.Character, ability string) []string {
+	if ability != "str" && ability != "dex" {
+		return nil
+	}
+	var reasons []string
+	for _, item := range []string{char.Armor, char.Shield} {
+		if item != "" && !equipment.IsArmorProficient(char, item) {
+			reasons = append(reasons, "not proficient with "+item)
+		}
+	}
+	return reasons
+}
+
+// encumbranceDisadvantage returns why the character has disadvantage on STR, DEX and CON rolls
+// from carrying too much
+func encumbranceDisadvantage(char *characterModel.Character, ability string) []string {
+	if ability != "str" && ability != "dex" && ability != "con" {
+		return nil
+	}
+	switch Encumbrance(char) {
+	case EncumbranceHeavy, EncumbranceOverCapacity:
+		return []string{EncumbranceHeavy}
+	}
+	return nil
+}
+
+// modeReasons returns the reasons for a roll asked for with advantage or disadvantage plus the automatic ones
+func modeReasons(asked bool, automatic ...string) []string {
+	var reasons []string
+	if asked {
+		reasons = append(reasons, "called for")
+	}
+	for _, r := range automatic {
+		if r != "" {
+			reasons = append(reasons, r)
+		}
+	}
+	return reasons
+}
+
+// RollCheck rolls a skill check. Armor that hampers stealth gives disadvantage on Stealth checks,
+// armor worn without proficiency on STR and DEX checks, heavy encumbrance on STR, DEX and CON checks
+// and conditions like poisoned on every check.
+func RollCheck(char *characterModel.Character, service *characterModel.CharacterService, skill string, advantage bool, disadvantage bool, rng dice.Source) (D20Roll, error) {
+	skill, ability, ok := SkillAbility(skill)
+	if !ok {
+		return D20Roll{}, fmt.Errorf("unknown skill %q", skill)
+	}
+	adv := modeReasons(advantage)
+	dis := modeReasons(disadvantage, armorDisadvantage(char, ability)...)
+	dis = append(dis, encumbranceDisadvantage(char, ability)...)
+	if skill == "stealth" && HasStealthDisadvantage(char) {
+		dis = append(dis, "wearing "+char.Armor)
+	}
+	dis = append(dis, condition.CheckDisadvantage(char)...)
+	label := strings.ToUpper(skill[:1]) + skill[1:] + " check"
+	return rollD20(label, CalculateSkill(char, service, skill), adv, dis, rng)
+}
+
+// RollSave rolls a saving throw for an ability ("str", "dex", ...). Conditions like paralyzed make
+// STR and DEX saves fail automatically; the roll is still made and reported.
+func RollSave(char *characterModel.Character, service *characterModel.CharacterService, ability string, advantage bool, disadvantage bool, rng dice.Source) (D20Roll, error) {
+	ability = strings.ToLower(strings.TrimSpace(ability))
+	if len(ability) > 3 {
+		ability = ability[:3]
+	}
+	if !containsAbility(ability) {
+		return D20Roll{}, fmt.Errorf("unknown ability %q", ability)
+	}
+	adv := modeReasons(advantage)
+	dis := modeReasons(disadvantage, armorDisadvantage(char, ability)...)
+	dis = append(dis, encumbranceDisadvantage(char, ability)...)
+	conditionDis, autoFail := condition.SaveModes(char, ability)
+	dis = append(dis, conditionDis...)
+	label := strings.ToUpper(ability) + " save"
+	roll, err := rollD20(label, CalculateSavingThrow(char, service, ability), adv, dis, rng)
+	roll.AutoFail = autoFail
+	return roll, err
+}
+
+// containsAbility reports whether the ability is one of the six abbreviations
+func containsAbility(ability string) bool {
+	for _, a := range Abilities {
+		if a == ability {
+			return true
+		}
+	}
+	return false
+}
+
+// AttackSlot returns the weapon and slot name for a slot given as "mainhand" or "off hand"
+func AttackSlot(char *characterModel.Character, slot string) (string, string, error) {
+	switch strings.ReplaceAll(strings.ToLower(slot), " ", "") {
+	case "mainhand", "main":
+		if char.MainHand == "" {
+			return "", "", fmt.Errorf("%s has no main hand weapon", char.Name)
+		}
+		return char.MainHand, "main hand", nil
+	case "offhand", "off":
+		if char.OffHand == "" {
+			return "", "", fmt.Errorf("%s has no off hand weapon", char.Name)
+		}
+		return char.OffHand, "off hand", nil
+	}
+	return "", "", fmt.Errorf("unknown slot %q, use mainhand or offhand", slot)
+}
+
+// RollAttack rolls an attack with the weapon in the slot against the target's AC, or against
+// any AC when targetAC is 0. A natural 20 always hits and is a critical hit, rolling the damage
+// dice twice; a natural 1 always misses. Conditions like poisoned or invisible change the roll,
+// and an incapacitated character can't attack at all.
+func RollAttack(char *characterModel.Character, service *characterModel.CharacterService, slot string, targetAC int, advantage bool, disadvantage bool, rng dice.Source) (AttackRoll, error) {
+	if reason := condition.Incapacitated(char); reason != "" {
+		return AttackRoll{}, fmt.Errorf("%s is %s and can't attack", char.Name, reason)
+	}
+	weapon, slotName, err := AttackSlot(char, slot)
+	if err != nil {
+		return AttackRoll{}, err
+	}
+	attack := CalculateWeaponAttack(char, service, weapon, slotName)
+	if attack.Damage == "-" {
+		return AttackRoll{}, fmt.Errorf("%s doesn't deal damage", weapon)
+	}
+	ability := "str"
+	if attack.Type == "ranged" {
+		ability = "dex"
+	}
+	conditionAdv, conditionDis := condition.AttackModes(char)
+	adv := modeReasons(advantage, conditionAdv...)
+	dis := modeReasons(disadvantage, append(armorDisadvantage(char, ability), conditionDis...)...)
+	dis = append(dis, encumbranceDisadvantage(char, ability)...)
+	d20, err := rollD20(attack.Name+" attack", attack.AttackBonus, adv, dis, rng)
+	if err != nil {
+		return AttackRoll{}, err
+	}
+
+	roll := AttackRoll{D20Roll: d20, Attack: attack, TargetAC: targetAC}
+	switch natural := d20.Natural(); {
+	case natural == 20:
+		roll.Hit, roll.Critical = true, true
+	case natural == 1:
+		roll.Hit = false
+	default:
+		roll.Hit = targetAC == 0 || d20.Total() >= targetAC
+	}
+	if !roll.Hit {
+		return roll, nil
+	}
+
+	damageDice, damageType, _ := strings.Cut(attack.Damage, " ")
+	expr, err := dice.Parse(damageDice)
+	if err != nil {
+		return roll, err
+	}
+	if roll.Critical {
+		expr = expr.Critical()
+	}
+	damage := expr.Roll(rng)
+	if damage.Total < 0 {
+		damage.Total = 0
+	}
+	roll.Damage, roll.DamageType = &damage, damageType
+	return roll, nil
+}
+
+// FormatD20Roll describes a roll, e.g. "Stealth check +5 with disadvantage (wearing chain mail):
+// 1d20+5 dis: [(14), 3] +5 = 8"
+func FormatD20Roll(r D20Roll) string {
+	mode := ""
+	switch {
+	case len(r.Advantage) > 0 && len(r.Disadvantage) > 0:
+		mode = fmt.Sprintf(" (advantage: %s; disadvantage: %s; they cancel out)",
+			strings.Join(r.Advantage, ", "), strings.Join(r.Disadvantage, ", "))
+	case len(r.Advantage) > 0:
+		mode = fmt.Sprintf(" with advantage (%s)", strings.Join(r.Advantage, ", "))
+	case len(r.Disadvantage) > 0:
+		mode = fmt.Sprintf(" with disadvantage (%s)", strings.Join(r.Disadvantage, ", "))
+	}
+	out := fmt.Sprintf("%s %+d%s: %s", r.Label, r.Modifier, mode, r.Result)
+	switch r.Natural() {
+	case 20:
+		out += ", natural 20!"
+	case 1:
+		out += ", natural 1!"
+	}
+	if len(r.AutoFail) > 0 {
+		out += fmt.Sprintf(", fails automatically (%s)", strings.Join(r.AutoFail, ", "))
+	}
+	return out
+}
+
+// FormatAttackRoll describes an attack roll and its outcome
+func FormatAttackRoll(r AttackRoll) string {
+	var sb strings.Builder
+	sb.WriteString(FormatD20Roll(r.D20Roll) + "\n")
+	switch {
+	case r.Critical:
+		sb.WriteString("Critical hit")
+	case !r.Hit && r.Natural() == 1:
+		sb.WriteString("Miss")
+	case !r.Hit:
+		sb.WriteString(fmt.Sprintf("Miss against AC %d", r.TargetAC))
+	case r.TargetAC > 0:
+		sb.WriteString(fmt.Sprintf("Hit against AC %d", r.TargetAC))
+	default:
+		sb.WriteString("If it hits")
+	}
+	if r.Damage != nil {
+		sb.WriteString(fmt.Sprintf(": %s", r.Damage))
+		if r.DamageType != "" {
+			sb.WriteString(" " + r.DamageType)
+		}
+		sb.WriteString(" damage")
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
@@ -3,6 +3,8 @@ package combat
 import (
 	"fmt"
 	characterModel "modules/dndcharactersheet/internal/character"
+	"modules/dndcharactersheet/internal/spellcasting"
+	"strings"
 )
 
 // FormatSpellcastingStats returns a formatted string for spellcasting stats
@@ -44,3 +46,105 @@ func CalculateSpellcastingStats(char *characterModel.Character, service *charact
 		SpellAttackBonus: char.Proficiency + abilityMod,
 	}
 }
+
+// CalculateSpellDamage returns the damage of casting a spell with a slot of the given level, or at the
+// character's level for cantrips, with the spell attack bonus or save DC the target faces.
+// Slot 0 casts a leveled spell at its own level.
+func CalculateSpellDamage(char *characterModel.Character, service *characterModel.CharacterService, spell spellcasting.Spell, slot int) (characterModel.SpellDamage, error) {
+	damage, ok := spellcasting.LookupSpellDamage(spell.Name)
+	if !ok {
+		return characterModel.SpellDamage{}, fmt.Errorf("%s deals no damage", strings.ToLower(spell.Name))
+	}
+	if spell.Level == 0 {
+		slot = 0
+	} else if slot == 0 {
+		slot = spell.Level
+	} else if slot < spell.Level {
+		return characterModel.SpellDamage{}, fmt.Errorf("%s is level %d and can't be cast with a level %d slot", strings.ToLower(spell.Name), spell.Level, slot)
+	}
+	dice, ok := damage.DiceAt(char.Level, slot)
+	if !ok {
+		return characterModel.SpellDamage{}, fmt.Errorf("no damage known for %s at level %d", strings.ToLower(spell.Name), slot)
+	}
+	stats := CalculateSpellcastingStats(char, service)
+	result := characterModel.SpellDamage{
+		Spell:  spell.Name,
+		Slot:   slot,
+		Damage: strings.TrimSpace(dice + " " + damage.Type),
+		Attack: damage.Attack,
+		Save:   damage.Save,
+	}
+	if damage.Attack != "" {
+		result.AttackBonus = stats.SpellAttackBonus
+	}
+	if damage.Save != "" {
+		result.SaveDC = stats.SpellSaveDC
+	}
+	return result, nil
+}
+
+// CalculateSpellDamageTable returns the damage of each damaging spell the character can cast,
+// at every slot level the character has that can cast it
+func CalculateSpellDamageTable(char *characterModel.Character, service *characterModel.CharacterService, spells []spellcasting.Spell) []characterModel.SpellDamage {
+	cs := spellcasting.ForCharacter(char)
+	var table []characterModel.SpellDamage
+	seen := map[string]bool{}
+	for _, name := range append(append(append([]string{}, cs.Cantrips...), cs.KnownSpells...), spellcasting.EffectivePrepared(&cs)...) {
+		spell := spellcasting.FindSpell(spells, name)
+		if spell == nil || seen[spell.Name] {
+			continue
+		}
+		seen[spell.Name] = true
+		if spell.Level == 0 {
+			if row, err := CalculateSpellDamage(char, service, *spell, 0); err == nil {
+				table = append(table, row)
+			}
+			continue
+		}
+		for slot := spell.Level; slot <= 9; slot++ {
+			if cs.SpellSlots[slot] == 0 {
+				continue
+			}
+			row, err := CalculateSpellDamage(char, service, *spell, slot)
+			if err != nil {
+				break
+			}
+			table = append(table, row)
+		}
+	}
+	return table
+}
+
+// FormatSpellDamage returns a damage row as text, e.g. "Fireball (level 3): 8d6 fire, DC 13 DEX save"
+func FormatSpellDamage(row characterModel.SpellDamage) string {
+	name := row.Spell
+	if row.Slot > 0 {
+		name += fmt.Sprintf(" (level %d)", row.Slot)
+	}
+	return fmt.Sprintf("%s: %s", name, FormatSpellDamageRoll(row))
+}
+
+// FormatSpellDamageRoll returns the damage of a row with the roll the target faces, e.g. "2d10 fire, +5 to hit"
+func FormatSpellDamageRoll(row characterModel.SpellDamage) string {
+	parts := []string{row.Damage}
+	if row.Attack != "" {
+		parts = append(parts, fmt.Sprintf("%+d to hit", row.AttackBonus))
+	}
+	if row.Save != "" {
+		parts = append(parts, fmt.Sprintf("DC %d %s save", row.SaveDC, strings.ToUpper(row.Save)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// FormatSpellDamageTable returns the spell damage table for the CLI sheet
+func FormatSpellDamageTable(table []characterModel.SpellDamage) string {
+	if len(table) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("Spell damage:\n")
+	for _, row := range table {
+		sb.WriteString("  " + FormatSpellDamage(row) + "\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,88 @@
+package combat
+
+import (
+	characterModel "modules/dndcharactersheet/internal/character"
+	"modules/dndcharactersheet/internal/spellcasting"
+	"reflect"
+	"testing"
+)
+
+var (
+	fireBolt     = spellcasting.Spell{Name: "Fire Bolt", Level: 0}
+	burningHands = spellcasting.Spell{Name: "Burning Hands", Level: 1}
+	guidingBolt  = spellcasting.Spell{Name: "Guiding Bolt", Level: 1}
+	fireball     = spellcasting.Spell{Name: "Fireball", Level: 3}
+)
+
+// wizard returns a wizard of the level with INT 16 and the proficiency bonus for the level
+func wizard(level int) *characterModel.Character {
+	return &characterModel.Character{Name: "Mialee", Class: "wizard", Level: level, Int: 16, Proficiency: 2 + (level-1)/4}
+}
+
+func TestCalculateSpellDamage(t *testing.T) {
+	service := characterModel.NewCharacterService()
+	cleric := &characterModel.Character{Name: "Jozan", Class: "cleric", Level: 1, Wis: 14, Proficiency: 2}
+	tests := []struct {
+		name  string
+		char  *characterModel.Character
+		spell spellcasting.Spell
+		slot  int
+		want  characterModel.SpellDamage
+	}{
+		{"slot 0 casts at the spell level", wizard(5), fireball, 0,
+			characterModel.SpellDamage{Spell: "Fireball", Slot: 3, Damage: "8d6 fire", Save: "dex", SaveDC: 14}},
+		{"upcast", wizard(5), fireball, 5,
+			characterModel.SpellDamage{Spell: "Fireball", Slot: 5, Damage: "10d6 fire", Save: "dex", SaveDC: 14}},
+		{"cantrip at level 1", wizard(1), fireBolt, 0,
+			characterModel.SpellDamage{Spell: "Fire Bolt", Damage: "1d10 fire", Attack: "ranged", AttackBonus: 5}},
+		{"cantrip at level 5", wizard(5), fireBolt, 0,
+			characterModel.SpellDamage{Spell: "Fire Bolt", Damage: "2d10 fire", Attack: "ranged", AttackBonus: 6}},
+		{"cantrip ignores the slot", wizard(11), fireBolt, 3,
+			characterModel.SpellDamage{Spell: "Fire Bolt", Damage: "3d10 fire", Attack: "ranged", AttackBonus: 7}},
+		{"cantrip at level 17", wizard(17), fireBolt, 0,
+			characterModel.SpellDamage{Spell: "Fire Bolt", Damage: "4d10 fire", Attack: "ranged", AttackBonus: 9}},
+		{"spell attack from WIS", cleric, guidingBolt, 0,
+			characterModel.SpellDamage{Spell: "Guiding Bolt", Slot: 1, Damage: "4d6 radiant", Attack: "ranged", AttackBonus: 4}},
+		{"spell attack upcast", cleric, guidingBolt, 2,
+			characterModel.SpellDamage{Spell: "Guiding Bolt", Slot: 2, Damage: "5d6 radiant", Attack: "ranged", AttackBonus: 4}},
+	}
+	for _, tt := range tests {
+		got, err := CalculateSpellDamage(tt.char, service, tt.spell, tt.slot)
+		if err != nil {
+			t.Errorf("%s: CalculateSpellDamage failed: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: CalculateSpellDamage = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := CalculateSpellDamage(wizard(5), service, fireball, 2); err == nil {
+		t.Errorf("casting fireball with a level 2 slot succeeded")
+	}
+}
+
+func TestCalculateSpellDamageTable(t *testing.T) {
+	char := wizard(5)
+	// no 2nd level slots: burning hands gets a row for the 1st and 3rd level slots only
+	char.Spellcasting = spellcasting.CharacterSpellcasting{
+		CasterType:     spellcasting.CasterFull,
+		Cantrips:       []string{"Fire Bolt"},
+		PreparedSpells: []string{"Burning Hands", "Fireball"},
+		SpellSlots:     map[int]int{1: 4, 3: 2},
+	}
+	spells := []spellcasting.Spell{fireBolt, burningHands, fireball}
+	var got []string
+	for _, row := range CalculateSpellDamageTable(char, characterModel.NewCharacterService(), spells) {
+		got = append(got, FormatSpellDamage(row))
+	}
+	want := []string{
+		"Fire Bolt: 2d10 fire, +6 to hit",
+		"Burning Hands (level 1): 3d6 fire, DC 14 DEX save",
+		"Burning Hands (level 3): 5d6 fire, DC 14 DEX save",
+		"Fireball (level 3): 8d6 fire, DC 14 DEX save",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CalculateSpellDamageTable =\n%q\nwant\n%q", got, want)
+	}
+}
@@ -0,0 +1,271 @@
+package condition
+
+import (
+	"fmt"
+	characterModel "modules/dndcharactersheet/internal/character"
+	"sort"
+	"strings"
+)
+
+// Condition is an SRD condition and the effects it has on the creature's own rolls and movement
+type Condition struct {
+	Name               string
+	Summary            string
+	Implies            []string // Conditions that come with this one, e.g. unconscious is also prone
+	AttackDisadvantage bool
+	AttackAdvantage    bool
+	CheckDisadvantage  bool
+	SaveDisadvantage   []string // Abilities whose saves have disadvantage
+	AutoFailSaves      []string // Abilities whose saves fail automatically
+	SpeedZero          bool
+	NoActions          bool // Can't take actions or reactions
+}
+
+// Exhaustion is the condition name used for exhaustion levels
+const Exhaustion = "exhaustion"
+
+// MaxExhaustion is the exhaustion level at which a creature dies
+const MaxExhaustion = 6
+
+// Conditions are the 14 SRD conditions, keyed by lowercase name
+var Conditions = map[string]Condition{
+	"blinded": {Name: "blinded", AttackDisadvantage: true,
+		Summary: "can't see, fails checks that need sight; attacks against it have advantage"},
+	"charmed": {Name: "charmed",
+		Summary: "can't attack the charmer; the charmer has advantage on social checks against it"},
+	"deafened": {Name: "deafened",
+		Summary: "can't hear, fails checks that need hearing"},
+	"frightened": {Name: "frightened", AttackDisadvantage: true, CheckDisadvantage: true,
+		Summary: "disadvantage on checks and attacks while the source is in sight; can't move closer to it"},
+	"grappled": {Name: "grappled", SpeedZero: true,
+		Summary: "speed 0"},
+	"incapacitated": {Name: "incapacitated", NoActions: true,
+		Summary: "can't take actions or reactions"},
+	"invisible": {Name: "invisible", AttackAdvantage: true,
+		Summary: "advantage on attacks; attacks against it have disadvantage"},
+	"paralyzed": {Name: "paralyzed", Implies: []string{"incapacitated"}, SpeedZero: true, AutoFailSaves: []string{"str", "dex"},
+		Summary: "can't move or speak, fails STR and DEX saves; hits from within 5 feet are critical"},
+	"petrified": {Name: "petrified", Implies: []string{"incapacitated"}, SpeedZero: true, AutoFailSaves: []string{"str", "dex"},
+		Summary: "turned to stone, fails STR and DEX saves, resists all damage"},
+	"poisoned": {Name: "poisoned", AttackDisadvantage: true, CheckDisadvantage: true,
+		Summary: "disadvantage on attacks and ability checks"},
+	"prone": {Name: "prone", AttackDisadvantage: true,
+		Summary: "disadvantage on attacks; melee attacks against it have advantage, ranged ones disadvantage"},
+	"restrained": {Name: "restrained", SpeedZero: true, AttackDisadvantage: true, SaveDisadvantage: []string{"dex"},
+		Summary: "speed 0, disadvantage on attacks and DEX saves; attacks against it have advantage"},
+	"stunned": {Name: "stunned", Implies: []string{"incapacitated"}, SpeedZero: true, AutoFailSaves: []string{"str", "dex"},
+		Summary: "can't move, fails STR and DEX saves; attacks against it have advantage"},
+	"unconscious": {Name: "unconscious", Implies: []string{"incapacitated", "prone"}, SpeedZero: true, AutoFailSaves: []string{"str", "dex"},
+		Summary: "unaware, drops what it holds, fails STR and DEX saves; hits from within 5 feet are critical"},
+}
+
+// ExhaustionEffects describes each exhaustion level; every level includes those below it
+var ExhaustionEffects = []string{
+	1: "disadvantage on ability checks",
+	2: "speed halved",
+	3: "disadvantage on attacks and saving throws",
+	4: "hit point maximum halved",
+	5: "speed 0",
+	6: "death",
+}
+
+// Names returns the condition names, sorted
+func Names() []string {
+	names := make([]string, 0, len(Conditions))
+	for name := range Conditions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Has reports whether the character has the condition, directly or through another one
+func Has(char *characterModel.Character, name string) bool {
+	for _, c := range Active(char) {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Add gives the character a condition, or levels more of exhaustion
+func Add(char *characterModel.Character, name string, levels int) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == Exhaustion {
+		if levels < 1 {
+			return fmt.Errorf("exhaustion levels must be positive")
+		}
+		if char.Exhaustion >= MaxExhaustion {
+			return fmt.Errorf("%s is already at exhaustion level %d", char.Name, MaxExhaustion)
+		}
+		char.Exhaustion = min(MaxExhaustion, char.Exhaustion+levels)
+		char.CurrentHP = min(char.CurrentHP, MaxHP(char))
+		if char.Exhaustion == MaxExhaustion {
+			char.Dead = true
+			char.CurrentHP = 0
+		}
+		return nil
+	}
+	if _, ok := Conditions[name]; !ok {
+		return fmt.Errorf("unknown condition %q", name)
+	}
+	for _, c := range char.Conditions {
+		if c == name {
+			return fmt.Errorf("%s is already %s", char.Name, name)
+		}
+	}
+	char.Conditions = append(char.Conditions, name)
+	return nil
+}
+
+// Remove ends a condition, or removes levels of exhaustion
+func Remove(char *characterModel.Character, name string, levels int) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == Exhaustion {
+		if char.Exhaustion == 0 {
+			return fmt.Errorf("%s isn't exhausted", char.Name)
+		}
+		char.Exhaustion = max(0, char.Exhaustion-max(1, levels))
+		return nil
+	}
+	for i, c := range char.Conditions {
+		if c == name {
+			char.Conditions = append(char.Conditions[:i], char.Conditions[i+1:]...)
+			return nil
+		}
+	}
+	if _, ok := Conditions[name]; !ok {
+		return fmt.Errorf("unknown condition %q", name)
+	}
+	return fmt.Errorf("%s isn't %s", char.Name, name)
+}
+
+// Active returns the character's conditions along with those they imply, without duplicates
+func Active(char *characterModel.Character) []Condition {
+	var active []Condition
+	seen := map[string]bool{}
+	var add func(name string)
+	add = func(name string) {
+		c, ok := Conditions[name]
+		if !ok || seen[name] {
+			return
+		}
+		seen[name] = true
+		active = append(active, c)
+		for _, implied := range c.Implies {
+			add(implied)
+		}
+	}
+	for _, name := range char.Conditions {
+		add(name)
+	}
+	return active
+}
+
+// exhaustionReason names the exhaustion level for roll annotations when it reaches the level
+func exhaustionReason(char *characterModel.Character, level int) []string {
+	if char.Exhaustion >= level {
+		return []string{fmt.Sprintf("exhaustion %d", char.Exhaustion)}
+	}
+	return nil
+}
+
+// AttackModes returns the conditions giving the character advantage and disadvantage on attack rolls
+func AttackModes(char *characterModel.Character) (advantage []string, disadvantage []string) {
+	for _, c := range Active(char) {
+		if c.AttackAdvantage {
+			advantage = append(advantage, c.Name)
+		}
+		if c.AttackDisadvantage {
+			disadvantage = append(disadvantage, c.Name)
+		}
+	}
+	return advantage, append(disadvantage, exhaustionReason(char, 3)...)
+}
+
+// CheckDisadvantage returns the conditions giving the character disadvantage on ability checks
+func CheckDisadvantage(char *characterModel.Character) []string {
+	var reasons []string
+	for _, c := range Active(char) {
+		if c.CheckDisadvantage {
+			reasons = append(reasons, c.Name)
+		}
+	}
+	return append(reasons, exhaustionReason(char, 1)...)
+}
+
+// SaveModes returns the conditions giving the character disadvantage on saves for the ability,
+// and those that make the save fail automatically
+func SaveModes(char *characterModel.Character, ability string) (disadvantage []string, autoFail []string) {
+	for _, c := range Active(char) {
+		for _, a := range c.SaveDisadvantage {
+			if a == ability {
+				disadvantage = append(disadvantage, c.Name)
+			}
+		}
+		for _, a := range c.AutoFailSaves {
+			if a == ability {
+				autoFail = append(autoFail, c.Name)
+			}
+		}
+	}
+	return append(disadvantage, exhaustionReason(char, 3)...), autoFail
+}
+
+// Speed applies conditions to a walking speed, returning the new speed and what changed it
+func Speed(char *characterModel.Character, speed int) (int, string) {
+	for _, c := range Active(char) {
+		if c.SpeedZero {
+			return 0, c.Name
+		}
+	}
+	switch {
+	case char.Exhaustion >= 5:
+		return 0, fmt.Sprintf("exhaustion %d", char.Exhaustion)
+	case char.Exhaustion >= 2:
+		return speed / 2, fmt.Sprintf("exhaustion %d", char.Exhaustion)
+	}
+	return speed, ""
+}
+
+// MaxHP returns the character's hit point maximum, halved from exhaustion level 4
+func MaxHP(char *characterModel.Character) int {
+	if char.Exhaustion >= 4 {
+		return char.MaxHP / 2
+	}
+	return char.MaxHP
+}
+
+// Incapacitated returns the condition that keeps the character from taking actions, or ""
+func Incapacitated(char *characterModel.Character) string {
+	for _, c := range Active(char) {
+		if c.NoActions {
+			// name the condition the character was given, e.g. stunned rather than incapacitated
+			for _, name := range char.Conditions {
+				for _, implied := range Conditions[name].Implies {
+					if implied == c.Name {
+						return name
+					}
+				}
+			}
+			return c.Name
+		}
+	}
+	return ""
+}
+
+// Notes describes each of the character's conditions and exhaustion level
+func Notes(char *characterModel.Character) []string {
+	var notes []string
+	for _, name := range char.Conditions {
+		if c, ok := Conditions[name]; ok {
+			notes = append(notes, fmt.Sprintf("%s: %s", c.Name, c.Summary))
+		}
+	}
+	if char.Exhaustion > 0 {
+		effects := ExhaustionEffects[1 : min(char.Exhaustion, MaxExhaustion)+1]
+		notes = append(notes, fmt.Sprintf("exhaustion %d: %s", char.Exhaustion, strings.Join(effects, ", ")))
+	}
+	return notes
+}
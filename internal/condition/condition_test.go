@@ -0,0 +1,115 @@
+package condition
+
+import (
+	characterModel "modules/dndcharactersheet/internal/character"
+	"reflect"
+	"testing"
+)
+
+func TestActiveImpliedConditions(t *testing.T) {
+	char := &characterModel.Character{Conditions: []string{"unconscious", "prone"}}
+	var names []string
+	for _, c := range Active(char) {
+		names = append(names, c.Name)
+	}
+	if want := []string{"unconscious", "incapacitated", "prone"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Active = %v, want %v", names, want)
+	}
+	if reason := Incapacitated(char); reason != "unconscious" {
+		t.Errorf("Incapacitated = %q, want unconscious", reason)
+	}
+	if _, autoFail := SaveModes(char, "dex"); !reflect.DeepEqual(autoFail, []string{"unconscious"}) {
+		t.Errorf("DEX saves fail automatically from %v, want unconscious", autoFail)
+	}
+}
+
+func TestAddRemove(t *testing.T) {
+	char := &characterModel.Character{Name: "Lidda"}
+	if err := Add(char, " Poisoned ", 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := Add(char, "poisoned", 0); err == nil {
+		t.Errorf("adding poisoned twice succeeded")
+	}
+	if err := Add(char, "sleepy", 0); err == nil {
+		t.Errorf("adding an unknown condition succeeded")
+	}
+	if !Has(char, "poisoned") || !reflect.DeepEqual(CheckDisadvantage(char), []string{"poisoned"}) {
+		t.Errorf("poisoned character: conditions %v, check disadvantage %v", char.Conditions, CheckDisadvantage(char))
+	}
+	if err := Remove(char, "poisoned", 0); err != nil || len(char.Conditions) != 0 {
+		t.Errorf("Remove(poisoned) left %v, %v", char.Conditions, err)
+	}
+	if err := Remove(char, "poisoned", 0); err == nil {
+		t.Errorf("removing a condition the character doesn't have succeeded")
+	}
+}
+
+func TestExhaustion(t *testing.T) {
+	tests := []struct {
+		level        int
+		speed        int
+		maxHP        int
+		checkDis     bool
+		attackDis    bool
+		saveDis      bool
+		dead         bool
+		currentAfter int
+	}{
+		{0, 30, 40, false, false, false, false, 40},
+		{1, 30, 40, true, false, false, false, 40},
+		{2, 15, 40, true, false, false, false, 40},
+		{3, 15, 40, true, true, true, false, 40},
+		{4, 15, 20, true, true, true, false, 20},
+		{5, 0, 20, true, true, true, false, 20},
+		{6, 0, 20, true, true, true, true, 0},
+	}
+	for _, tt := range tests {
+		char := &characterModel.Character{Name: "Tordek", MaxHP: 40, CurrentHP: 40}
+		if tt.level > 0 {
+			if err := Add(char, Exhaustion, tt.level); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if speed, _ := Speed(char, 30); speed != tt.speed {
+			t.Errorf("exhaustion %d: speed %d, want %d", tt.level, speed, tt.speed)
+		}
+		if MaxHP(char) != tt.maxHP || char.CurrentHP != tt.currentAfter {
+			t.Errorf("exhaustion %d: %d/%d hit points, want %d/%d", tt.level, char.CurrentHP, MaxHP(char), tt.currentAfter, tt.maxHP)
+		}
+		_, attackDis := AttackModes(char)
+		saveDis, _ := SaveModes(char, "wis")
+		if (len(CheckDisadvantage(char)) > 0) != tt.checkDis || (len(attackDis) > 0) != tt.attackDis || (len(saveDis) > 0) != tt.saveDis {
+			t.Errorf("exhaustion %d: check %v, attack %v, save %v disadvantage", tt.level, CheckDisadvantage(char), attackDis, saveDis)
+		}
+		if char.Dead != tt.dead {
+			t.Errorf("exhaustion %d: Dead = %v", tt.level, char.Dead)
+		}
+	}
+}
+
+func TestExhaustionLevels(t *testing.T) {
+	char := &characterModel.Character{Name: "Tordek", MaxHP: 40, CurrentHP: 40}
+	if err := Add(char, Exhaustion, 0); err == nil {
+		t.Errorf("adding 0 levels of exhaustion succeeded")
+	}
+	Add(char, Exhaustion, 2)
+	Add(char, Exhaustion, 9)
+	if char.Exhaustion != MaxExhaustion {
+		t.Errorf("exhaustion %d, want it capped at %d", char.Exhaustion, MaxExhaustion)
+	}
+	if err := Add(char, Exhaustion, 1); err == nil {
+		t.Errorf("adding exhaustion past level 6 succeeded")
+	}
+	Remove(char, Exhaustion, 4)
+	if char.Exhaustion != 2 {
+		t.Errorf("exhaustion %d after removing 4 levels, want 2", char.Exhaustion)
+	}
+}
+
+func TestSpeedZero(t *testing.T) {
+	char := &characterModel.Character{Conditions: []string{"grappled"}}
+	if speed, reason := Speed(char, 30); speed != 0 || reason != "grappled" {
+		t.Errorf("grappled speed %d (%s), want 0 (grappled)", speed, reason)
+	}
+}
@@ -0,0 +1,48 @@
+package currency
+
+import (
+	"fmt"
+	characterModel "modules/dndcharactersheet/internal/character"
+	"strings"
+	"time"
+)
+
+// Record appends a transaction to the character's ledger with the wallet balance after it
+func Record(char *characterModel.Character, txType string, item string, quantity int, copper int) {
+	char.Ledger = append(char.Ledger, characterModel.Transaction{
+		Time:     time.Now().Format(time.RFC3339),
+		Type:     txType,
+		Item:     item,
+		Quantity: quantity,
+		Copper:   copper,
+		Balance:  Total(char.Wallet),
+	})
+}
+
+// FormatLedger returns a formatted string listing the character's transactions
+func FormatLedger(char *characterModel.Character) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Ledger for %s:\n", char.Name))
+	if len(char.Ledger) == 0 {
+		sb.WriteString("  no transactions\n")
+		return sb.String()
+	}
+	spent, received := 0, 0
+	for _, t := range char.Ledger {
+		sign := "+"
+		switch t.Type {
+		case "buy", "withdraw", "copy":
+			sign = "-"
+			spent += t.Copper
+		default:
+			received += t.Copper
+		}
+		line := fmt.Sprintf("  %s %-8s %s%s", t.Time, t.Type, sign, FormatCopper(t.Copper))
+		if t.Item != "" {
+			line += fmt.Sprintf(" (%dx %s)", t.Quantity, t.Item)
+		}
+		sb.WriteString(fmt.Sprintf("%s, balance %s\n", line, FormatCopper(t.Balance)))
+	}
+	sb.WriteString(fmt.Sprintf("  total spent %s, total received %s\n", FormatCopper(spent), FormatCopper(received)))
+	return sb.String()
+}
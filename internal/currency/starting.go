@@ -0,0 +1,38 @@
+package currency
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// RollStartingGold rolls a class's starting wealth formula like "5d4x10" or "5d4" and returns gold pieces
+func RollStartingGold(formula string, rng *rand.Rand) (int, error) {
+	formula = strings.ToLower(strings.ReplaceAll(formula, " ", ""))
+	dice, multiplier, hasMultiplier := strings.Cut(formula, "x")
+	count, sides, ok := strings.Cut(dice, "d")
+	if !ok {
+		return 0, fmt.Errorf("invalid starting gold %q", formula)
+	}
+	n, err := strconv.Atoi(count)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid starting gold %q", formula)
+	}
+	s, err := strconv.Atoi(sides)
+	if err != nil || s <= 0 {
+		return 0, fmt.Errorf("invalid starting gold %q", formula)
+	}
+	m := 1
+	if hasMultiplier {
+		m, err = strconv.Atoi(multiplier)
+		if err != nil || m <= 0 {
+			return 0, fmt.Errorf("invalid starting gold %q", formula)
+		}
+	}
+	total := 0
+	for i := 0; i < n; i++ {
+		total += rng.Intn(s) + 1
+	}
+	return total * m, nil
+}
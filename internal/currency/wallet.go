@@ -0,0 +1,172 @@
+package currency
+
+import (
+	"fmt"
+	characterModel "modules/dndcharactersheet/internal/character"
+	"strconv"
+	"strings"
+)
+
+// Coin values in copper pieces
+const (
+	CP = 1
+	SP = 10
+	EP = 50
+	GP = 100
+	PP = 1000
+)
+
+// denominations lists the coins from most to least valuable
+var denominations = []struct {
+	unit  string
+	value int
+}{
+	{"pp", PP},
+	{"gp", GP},
+	{"ep", EP},
+	{"sp", SP},
+	{"cp", CP},
+}
+
+// ParseCost parses an SRD cost like "15 gp" or "5cp" into copper pieces
+func ParseCost(cost string) (int, error) {
+	cost = strings.ToLower(strings.TrimSpace(cost))
+	if cost == "" {
+		return 0, fmt.Errorf("empty cost")
+	}
+	for _, d := range denominations {
+		if !strings.HasSuffix(cost, d.unit) {
+			continue
+		}
+		amount := strings.TrimSpace(strings.TrimSuffix(cost, d.unit))
+		amount = strings.ReplaceAll(amount, ",", "")
+		n, err := strconv.Atoi(amount)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid cost %q", cost)
+		}
+		return n * d.value, nil
+	}
+	return 0, fmt.Errorf("invalid cost %q: expected a cp, sp, ep, gp or pp amount", cost)
+}
+
+// UnitValue returns the copper value of a coin unit like "gp", or 0 if it isn't one
+func UnitValue(unit string) int {
+	unit = strings.ToLower(strings.TrimSpace(unit))
+	for _, d := range denominations {
+		if d.unit == unit {
+			return d.value
+		}
+	}
+	return 0
+}
+
+// FormatCopper formats a copper amount using gold, silver and copper, e.g. "2 gp 5 sp"
+func FormatCopper(copper int) string {
+	if copper == 0 {
+		return "0 cp"
+	}
+	var parts []string
+	for _, d := range []struct {
+		unit  string
+		value int
+	}{{"gp", GP}, {"sp", SP}, {"cp", CP}} {
+		if n := copper / d.value; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, d.unit))
+			copper -= n * d.value
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// Total returns the value of all coins in the wallet in copper pieces
+func Total(w characterModel.Wallet) int {
+	return w.CP*CP + w.SP*SP + w.EP*EP + w.GP*GP + w.PP*PP
+}
+
+// FormatWallet returns the coin counts in the wallet, e.g. "0 pp, 12 gp, 0 ep, 4 sp, 7 cp"
+func FormatWallet(w characterModel.Wallet) string {
+	return fmt.Sprintf("%d pp, %d gp, %d ep, %d sp, %d cp (total %s)", w.PP, w.GP, w.EP, w.SP, w.CP, FormatCopper(Total(w)))
+}
+
+// coins returns a pointer to the wallet's count for a coin unit
+func coins(w *characterModel.Wallet, unit string) *int {
+	switch unit {
+	case "pp":
+		return &w.PP
+	case "gp":
+		return &w.GP
+	case "ep":
+		return &w.EP
+	case "sp":
+		return &w.SP
+	default:
+		return &w.CP
+	}
+}
+
+// Receive adds copper to the wallet as gold, silver and copper coins
+func Receive(w *characterModel.Wallet, copper int) {
+	w.GP += copper / GP
+	copper %= GP
+	w.SP += copper / SP
+	w.CP += copper % SP
+}
+
+// Deposit parses an amount like "15 gp" and adds it to the wallet in that coin, returning its copper value
+func Deposit(w *characterModel.Wallet, amount string) (int, error) {
+	copper, err := ParseCost(amount)
+	if err != nil {
+		return 0, err
+	}
+	unit := strings.TrimSpace(strings.TrimLeft(strings.ToLower(amount), "0123456789, "))
+	*coins(w, unit) += copper / UnitValue(unit)
+	return copper, nil
+}
+
+// Pay removes the price from the wallet. It first pays exactly with the largest coins that fit,
+// then breaks the smallest coin that covers the rest and takes the change back in gold, silver and copper.
+func Pay(w *characterModel.Wallet, copper int) error {
+	if copper < 0 {
+		return fmt.Errorf("invalid amount")
+	}
+	if Total(*w) < copper {
+		return fmt.Errorf("not enough coins: need %s, have %s", FormatCopper(copper), FormatCopper(Total(*w)))
+	}
+
+	remaining := copper
+	for _, d := range denominations {
+		have := coins(w, d.unit)
+		use := min(*have, remaining/d.value)
+		*have -= use
+		remaining -= use * d.value
+	}
+
+	for remaining > 0 {
+		// smallest coin that covers what is left, otherwise the largest coin available
+		var pick string
+		for i := len(denominations) - 1; i >= 0; i-- {
+			d := denominations[i]
+			if *coins(w, d.unit) > 0 && d.value >= remaining {
+				pick = d.unit
+				break
+			}
+		}
+		if pick == "" {
+			for _, d := range denominations {
+				if *coins(w, d.unit) > 0 {
+					pick = d.unit
+					break
+				}
+			}
+		}
+		*coins(w, pick)--
+		value := UnitValue(pick)
+		if value > remaining {
+			Receive(w, value-remaining)
+			remaining = 0
+		} else {
+			remaining -= value
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package currency
+
+import (
+	characterModel "modules/dndcharactersheet/internal/character"
+	"testing"
+)
+
+func TestPay(t *testing.T) {
+	tests := []struct {
+		name   string
+		wallet characterModel.Wallet
+		price  int
+		want   characterModel.Wallet
+	}{
+		{"exact", characterModel.Wallet{GP: 2, SP: 5}, 250, characterModel.Wallet{}},
+		{"largest coins first", characterModel.Wallet{GP: 3, SP: 10, CP: 4}, 204, characterModel.Wallet{GP: 1, SP: 10}},
+		{"change from gold", characterModel.Wallet{GP: 1}, 35, characterModel.Wallet{SP: 6, CP: 5}},
+		{"change from platinum", characterModel.Wallet{PP: 1, CP: 3}, 5, characterModel.Wallet{GP: 9, SP: 9, CP: 8}},
+		{"break the smallest coin that covers", characterModel.Wallet{PP: 1, GP: 1}, 50, characterModel.Wallet{PP: 1, SP: 5}},
+		{"nothing", characterModel.Wallet{EP: 1}, 0, characterModel.Wallet{EP: 1}},
+	}
+	for _, tt := range tests {
+		w := tt.wallet
+		if err := Pay(&w, tt.price); err != nil {
+			t.Errorf("%s: Pay(%+v, %d) failed: %v", tt.name, tt.wallet, tt.price, err)
+			continue
+		}
+		if w != tt.want {
+			t.Errorf("%s: Pay(%+v, %d) left %+v, want %+v", tt.name, tt.wallet, tt.price, w, tt.want)
+		}
+		if Total(tt.wallet)-Total(w) != tt.price {
+			t.Errorf("%s: Pay(%+v, %d) took %d cp", tt.name, tt.wallet, tt.price, Total(tt.wallet)-Total(w))
+		}
+	}
+}
+
+func TestPayNotEnough(t *testing.T) {
+	w := characterModel.Wallet{SP: 3, CP: 9}
+	if err := Pay(&w, 100); err == nil {
+		t.Errorf("Pay(100) with 39 cp succeeded")
+	}
+	if want := (characterModel.Wallet{SP: 3, CP: 9}); w != want {
+		t.Errorf("failed Pay changed the wallet to %+v", w)
+	}
+	if err := Pay(&w, -1); err == nil {
+		t.Errorf("Pay(-1) succeeded")
+	}
+}
+
+func TestParseCost(t *testing.T) {
+	tests := []struct {
+		cost string
+		want int
+	}{
+		{"15 gp", 1500},
+		{"5cp", 5},
+		{"2 SP", 20},
+		{"1 ep", 50},
+		{"1,000 gp", 100000},
+		{"3 pp", 3000},
+	}
+	for _, tt := range tests {
+		got, err := ParseCost(tt.cost)
+		if err != nil {
+			t.Errorf("ParseCost(%q) failed: %v", tt.cost, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseCost(%q) = %d, want %d", tt.cost, got, tt.want)
+		}
+	}
+	for _, cost := range []string{"", "gp", "-5 gp", "5 dollars", "five gp"} {
+		if _, err := ParseCost(cost); err == nil {
+			t.Errorf("ParseCost(%q) succeeded", cost)
+		}
+	}
+}
+
+func TestFormatCopper(t *testing.T) {
+	tests := map[int]string{0: "0 cp", 5: "5 cp", 250: "2 gp 5 sp", 1203: "12 gp 3 cp"}
+	for copper, want := range tests {
+		if got := FormatCopper(copper); got != want {
+			t.Errorf("FormatCopper(%d) = %q, want %q", copper, got, want)
+		}
+	}
+}
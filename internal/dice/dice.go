@@ -0,0 +1,386 @@
+package dice
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Source is where dice get their numbers: Intn(n) returns 0 to n-1. *rand.Rand satisfies it,
+// and tests can pass a fixed sequence from Faces.
+type Source interface {
+	Intn(n int) int
+}
+
+// NewSource returns a random source for the seed, seeded from the clock when seed is 0
+func NewSource(seed int64) *rand.Rand {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return rand.New(rand.NewSource(seed))
+}
+
+// Faces returns a Source that rolls the given faces in order, whatever the die, for tests that
+// need known rolls. It panics once the faces run out.
+func Faces(faces ...int) Source {
+	return &faceSource{faces: faces}
+}
+
+// faceSource is the Source returned by Faces
+type faceSource struct {
+	faces []int
+}
+
+func (s *faceSource) Intn(n int) int {
+	if len(s.faces) == 0 {
+		panic("dice: no faces left to roll")
+	}
+	face := s.faces[0]
+	s.faces = s.faces[1:]
+	return face - 1
+}
+
+// Limits on a single dice term, to keep typos like "1000d1000" from running away
+const (
+	MaxDice  = 100
+	MaxSides = 1000
+)
+
+// Term is one part of an expression: a group of dice like "4d6kh3" or a flat modifier like "-1"
+type Term struct {
+	Sign     int // +1 or -1
+	Count    int // Dice rolled, 0 for a flat modifier
+	Sides    int
+	Flat     int
+	KeepHigh int // Keep only this many of the highest dice, 0 keeps all
+	KeepLow  int // Keep only this many of the lowest dice, 0 keeps all
+	Reroll   int // Reroll, once, dice showing this or lower
+}
+
+// Expression is a parsed dice expression
+type Expression struct {
+	Text         string
+	Terms        []Term
+	Advantage    bool // Roll the d20 twice and keep the higher
+	Disadvantage bool // Roll the d20 twice and keep the lower
+	Min          int  // Every die counts as at least this
+}
+
+// Die is one die rolled for a term
+type Die struct {
+	Sides int
+	Rolls []int // Every roll of the die; a reroll comes after the roll it replaced
+	Value int   // The value counted, after any reroll and minimum
+	Kept  bool
+}
+
+// Group is the dice rolled for one term of an expression
+type Group struct {
+	Term  Term
+	Dice  []Die
+	Total int // Signed total of the kept dice, or the flat modifier
+}
+
+// Result is a rolled expression with every die that went into it
+type Result struct {
+	Expression string
+	Groups     []Group
+	Total      int
+}
+
+var (
+	diceTerm   = regexp.MustCompile(`^(\d*)d(\d+|%)((?:kh|kl|k|r)\d+)*$`)
+	modifier   = regexp.MustCompile(`(kh|kl|k|r)(\d+)`)
+	flatTerm   = regexp.MustCompile(`^\d+$`)
+	minKeyword = regexp.MustCompile(`^min(\d*)$`)
+)
+
+// Parse reads an expression like "2d6+3", "1d20+5 adv", "4d6kh3", "8d6 min 3" or "1d8r1".
+// Terms are joined with + or -; "kh"/"kl" keep the highest/lowest dice, "r" rerolls low dice once,
+// "adv"/"dis" roll a d20 with advantage or disadvantage and "min" sets the lowest value a die counts as.
+func Parse(text string) (Expression, error) {
+	expr := Expression{Text: strings.TrimSpace(text)}
+	var arithmetic strings.Builder
+	words := strings.Fields(strings.ToLower(text))
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		switch {
+		case word == "adv" || word == "advantage":
+			expr.Advantage = true
+		case word == "dis" || word == "disadvantage":
+			expr.Disadvantage = true
+		case minKeyword.MatchString(word):
+			value := minKeyword.FindStringSubmatch(word)[1]
+			if value == "" {
+				if i+1 == len(words) {
+					return expr, fmt.Errorf("%q: min needs a number", text)
+				}
+				i++
+				value = words[i]
+			}
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return expr, fmt.Errorf("%q: min needs a positive number", text)
+			}
+			expr.Min = n
+		default:
+			arithmetic.WriteString(word)
+		}
+	}
+
+	body := arithmetic.String()
+	if body == "" {
+		return expr, fmt.Errorf("%q has no dice to roll", text)
+	}
+	sign := 1
+	start := 0
+	if body[0] == '+' || body[0] == '-' {
+		start = 1
+		if body[0] == '-' {
+			sign = -1
+		}
+	}
+	for i := start; i <= len(body); i++ {
+		if i < len(body) && body[i] != '+' && body[i] != '-' {
+			continue
+		}
+		term, err := parseTerm(body[start:i])
+		if err != nil {
+			return expr, fmt.Errorf("%q: %v", text, err)
+		}
+		term.Sign = sign
+		expr.Terms = append(expr.Terms, term)
+		if i < len(body) {
+			sign = 1
+			if body[i] == '-' {
+				sign = -1
+			}
+		}
+		start = i + 1
+	}
+	for _, term := range expr.Terms {
+		if term.Count > 0 && expr.Min > term.Sides {
+			return expr, fmt.Errorf("%q: min %d is more than a d%d can roll", text, expr.Min, term.Sides)
+		}
+	}
+
+	if expr.Advantage && expr.Disadvantage {
+		// advantage and disadvantage cancel out
+		expr.Advantage, expr.Disadvantage = false, false
+	} else if expr.Advantage || expr.Disadvantage {
+		if err := expr.applyAdvantage(); err != nil {
+			return expr, fmt.Errorf("%q: %v", text, err)
+		}
+	}
+	return expr, nil
+}
+
+// parseTerm reads a single term like "4d6kh3" or "5"
+func parseTerm(text string) (Term, error) {
+	if flatTerm.MatchString(text) {
+		n, _ := strconv.Atoi(text)
+		return Term{Flat: n}, nil
+	}
+	m := diceTerm.FindStringSubmatch(text)
+	if m == nil {
+		return Term{}, fmt.Errorf("invalid term %q", text)
+	}
+	term := Term{Count: 1}
+	if m[1] != "" {
+		term.Count, _ = strconv.Atoi(m[1])
+	}
+	if m[2] == "%" {
+		term.Sides = 100
+	} else {
+		term.Sides, _ = strconv.Atoi(m[2])
+	}
+	if term.Count < 1 || term.Count > MaxDice {
+		return term, fmt.Errorf("%s: roll between 1 and %d dice", text, MaxDice)
+	}
+	if term.Sides < 1 || term.Sides > MaxSides {
+		return term, fmt.Errorf("%s: dice have between 1 and %d sides", text, MaxSides)
+	}
+	for _, mod := range modifier.FindAllStringSubmatch(text[strings.Index(text, "d")+1:], -1) {
+		n, _ := strconv.Atoi(mod[2])
+		if n < 1 && mod[1] != "r" {
+			return term, fmt.Errorf("%s: keep at least one die", text)
+		}
+		switch mod[1] {
+		case "kh", "k":
+			term.KeepHigh = n
+		case "kl":
+			term.KeepLow = n
+		case "r":
+			term.Reroll = n
+		}
+	}
+	if term.KeepHigh > 0 && term.KeepLow > 0 {
+		return term, fmt.Errorf("%s: keep either the highest or the lowest dice", text)
+	}
+	if term.KeepHigh > term.Count || term.KeepLow > term.Count {
+		return term, fmt.Errorf("%s: can't keep more dice than are rolled", text)
+	}
+	if term.Reroll >= term.Sides {
+		return term, fmt.Errorf("%s: rerolling %d or lower would reroll every die", text, term.Reroll)
+	}
+	return term, nil
+}
+
+// applyAdvantage turns the expression's single d20 into two d20s keeping the higher or lower
+func (e *Expression) applyAdvantage() error {
+	found := -1
+	for i, t := range e.Terms {
+		if t.Sides == 20 && t.Count == 1 {
+			if found >= 0 {
+				return fmt.Errorf("advantage needs a single d20")
+			}
+			found = i
+		}
+	}
+	if found < 0 {
+		return fmt.Errorf("advantage needs a d20 roll")
+	}
+	e.Terms[found].Count = 2
+	if e.Advantage {
+		e.Terms[found].KeepHigh = 1
+	} else {
+		e.Terms[found].KeepLow = 1
+	}
+	return nil
+}
+
+// Critical returns the expression with twice as many dice in each dice term, for a critical hit
+func (e Expression) Critical() Expression {
+	crit := e
+	crit.Text = e.Text + " critical"
+	crit.Terms = make([]Term, len(e.Terms))
+	for i, t := range e.Terms {
+		if t.Count > 0 {
+			t.Count *= 2
+			t.KeepHigh *= 2
+			t.KeepLow *= 2
+		}
+		crit.Terms[i] = t
+	}
+	return crit
+}
+
+// Roll rolls every term of the expression
+func (e Expression) Roll(rng Source) Result {
+	result := Result{Expression: e.Text}
+	for _, term := range e.Terms {
+		group := Group{Term: term}
+		if term.Count == 0 {
+			group.Total = term.Sign * term.Flat
+		} else {
+			group.Dice = e.rollDice(term, rng)
+			for _, d := range group.Dice {
+				if d.Kept {
+					group.Total += term.Sign * d.Value
+				}
+			}
+		}
+		result.Groups = append(result.Groups, group)
+		result.Total += group.Total
+	}
+	return result
+}
+
+// rollDice rolls a term's dice, rerolling low ones and marking those kept
+func (e Expression) rollDice(term Term, rng Source) []Die {
+	dice := make([]Die, term.Count)
+	for i := range dice {
+		roll := rng.Intn(term.Sides) + 1
+		d := Die{Sides: term.Sides, Rolls: []int{roll}}
+		if term.Reroll > 0 && roll <= term.Reroll {
+			roll = rng.Intn(term.Sides) + 1
+			d.Rolls = append(d.Rolls, roll)
+		}
+		d.Value = max(roll, e.Min)
+		d.Kept = term.KeepHigh == 0 && term.KeepLow == 0
+		dice[i] = d
+	}
+	keep := max(term.KeepHigh, term.KeepLow)
+	if keep > 0 {
+		order := make([]int, len(dice))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(a, b int) bool {
+			if term.KeepHigh > 0 {
+				return dice[order[a]].Value > dice[order[b]].Value
+			}
+			return dice[order[a]].Value < dice[order[b]].Value
+		})
+		for _, i := range order[:keep] {
+			dice[i].Kept = true
+		}
+	}
+	return dice
+}
+
+// Roll parses and rolls an expression
+func Roll(text string, rng Source) (Result, error) {
+	expr, err := Parse(text)
+	if err != nil {
+		return Result{}, err
+	}
+	return expr.Roll(rng), nil
+}
+
+// Natural returns the kept face of the first d20 rolled, or 0 when the expression has no d20
+func (r Result) Natural() int {
+	for _, g := range r.Groups {
+		if g.Term.Sides != 20 {
+			continue
+		}
+		for _, d := range g.Dice {
+			if d.Kept {
+				return d.Rolls[len(d.Rolls)-1]
+			}
+		}
+	}
+	return 0
+}
+
+// String returns the roll with every die, e.g. "1d20+5 adv: [17, (4)] +5 = 22". Dropped dice are in
+// parentheses and rerolled dice show the roll they replaced, "1>6".
+func (r Result) String() string {
+	var sb strings.Builder
+	sb.WriteString(r.Expression + ":")
+	for i, g := range r.Groups {
+		sign := "+"
+		if g.Term.Sign < 0 {
+			sign = "-"
+		}
+		if g.Term.Count == 0 {
+			sb.WriteString(fmt.Sprintf(" %s%d", sign, g.Term.Flat))
+			continue
+		}
+		if i > 0 || g.Term.Sign < 0 {
+			sb.WriteString(" " + sign)
+		}
+		faces := make([]string, len(g.Dice))
+		for j, d := range g.Dice {
+			face := make([]string, len(d.Rolls))
+			for k, roll := range d.Rolls {
+				face[k] = strconv.Itoa(roll)
+			}
+			f := strings.Join(face, ">")
+			if d.Value != d.Rolls[len(d.Rolls)-1] {
+				f += fmt.Sprintf("=%d", d.Value)
+			}
+			if !d.Kept {
+				f = "(" + f + ")"
+			}
+			faces[j] = f
+		}
+		sb.WriteString(" [" + strings.Join(faces, ", ") + "]")
+	}
+	sb.WriteString(fmt.Sprintf(" = %d", r.Total))
+	return sb.String()
+}
@@ -0,0 +1,81 @@
+package dice
+
+import "testing"
+
+func roll(t *testing.T, expr string, faces ...int) Result {
+	t.Helper()
+	result, err := Roll(expr, Faces(faces...))
+	if err != nil {
+		t.Fatalf("Roll(%q) failed: %v", expr, err)
+	}
+	return result
+}
+
+func TestRollTotals(t *testing.T) {
+	tests := []struct {
+		expr  string
+		faces []int
+		total int
+		text  string
+	}{
+		{"2d6+3", []int{4, 5}, 12, "2d6+3: [4, 5] +3 = 12"},
+		{"1d20+5 adv", []int{7, 16}, 21, "1d20+5 adv: [(7), 16] +5 = 21"},
+		{"1d20+5 dis", []int{7, 16}, 12, "1d20+5 dis: [7, (16)] +5 = 12"},
+		{"1d20 adv dis", []int{7}, 7, "1d20 adv dis: [7] = 7"},
+		{"4d6kh3", []int{3, 6, 1, 5}, 14, "4d6kh3: [3, 6, (1), 5] = 14"},
+		{"2d20kl1", []int{12, 9}, 9, "2d20kl1: [(12), 9] = 9"},
+		{"8d6 min 3", []int{1, 2, 3, 4, 5, 6, 1, 6}, 33, "8d6 min 3: [1=3, 2=3, 3, 4, 5, 6, 1=3, 6] = 33"},
+		{"1d8r1", []int{1, 6}, 6, "1d8r1: [1>6] = 6"},
+		{"1d8r1", []int{1, 1}, 1, "1d8r1: [1>1] = 1"},
+		{"2d6-1d4-1", []int{3, 3, 2}, 3, "2d6-1d4-1: [3, 3] - [2] -1 = 3"},
+		{"d%", []int{42}, 42, "d%: [42] = 42"},
+	}
+	for _, tt := range tests {
+		result := roll(t, tt.expr, tt.faces...)
+		if result.Total != tt.total {
+			t.Errorf("%s with %v: total %d, want %d", tt.expr, tt.faces, result.Total, tt.total)
+		}
+		if got := result.String(); got != tt.text {
+			t.Errorf("%s with %v: %q, want %q", tt.expr, tt.faces, got, tt.text)
+		}
+	}
+}
+
+func TestNatural(t *testing.T) {
+	if got := roll(t, "1d20+3 adv", 20, 4).Natural(); got != 20 {
+		t.Errorf("Natural() = %d, want 20", got)
+	}
+	if got := roll(t, "1d20+3 dis", 20, 4).Natural(); got != 4 {
+		t.Errorf("Natural() = %d, want 4", got)
+	}
+	if got := roll(t, "2d6", 1, 1).Natural(); got != 0 {
+		t.Errorf("Natural() without a d20 = %d, want 0", got)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{"", "adv", "2d6 adv", "1d20+1d20 adv", "3x", "2d6kh3", "1d6r6", "0d6", "1d20 min", "1d6+", "1d6 min 9", "1d4+1d8 min 5", "4d6kh0", "4d6kl0", "4d6k0"} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", expr)
+		}
+	}
+}
+
+func TestSeededRollsRepeat(t *testing.T) {
+	first, _ := Roll("10d20", NewSource(42))
+	second, _ := Roll("10d20", NewSource(42))
+	if first.String() != second.String() {
+		t.Errorf("same seed rolled %q and %q", first, second)
+	}
+}
+
+func TestCritical(t *testing.T) {
+	expr, err := Parse("1d8+3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := expr.Critical().Roll(Faces(2, 8))
+	if got, want := result.String(), "1d8+3 critical: [2, 8] +3 = 13"; got != want {
+		t.Errorf("critical roll %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,287 @@
+package encounter
+
+import (
+	"encoding/json"
+	"fmt"
+	characterModel "modules/dndcharactersheet/internal/character"
+	"modules/dndcharactersheet/internal/combat"
+	"modules/dndcharactersheet/internal/condition"
+	"modules/dndcharactersheet/internal/dice"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// File is where the encounter in progress is kept between commands
+const File = "encounter.json"
+
+// Encounter is a fight in progress: its participants in initiative order, whose turn it is and the round
+type Encounter struct {
+	Round        int           `json:"round"`
+	Turn         int           `json:"turn"` // Index of the participant whose turn it is
+	Participants []Participant `json:"participants"`
+}
+
+// Participant is a stored character or an ad-hoc monster taking part in the encounter
+type Participant struct {
+	Name       string                    `json:"name"`
+	Initiative int                       `json:"initiative"`
+	Roll       string                    `json:"roll"`              // The initiative roll, e.g. "1d20+2: [14] +2 = 16"
+	Dex        int                       `json:"dex"`               // DEX score, to break initiative ties
+	Monster    *characterModel.Character `json:"monster,omitempty"` // Monster stats; characters live in characters.json
+	Out        bool                      `json:"out,omitempty"`     // Dead or defeated, skipped in the turn order
+}
+
+// MonsterSpec describes ad-hoc monsters as "NAME[*COUNT]:AC:HP[:DEX]", where HP can be a dice
+// expression like "2d6" rolled for each monster, e.g. "Goblin*3:15:2d6:14"
+type MonsterSpec struct {
+	Name  string
+	Count int
+	AC    int
+	HP    string
+	Dex   int
+}
+
+// ParseMonster reads a monster spec like "Goblin*3:15:2d6:14"; DEX defaults to 10
+func ParseMonster(spec string) (MonsterSpec, error) {
+	parts := strings.Split(strings.TrimSpace(spec), ":")
+	if len(parts) < 3 || len(parts) > 4 {
+		return MonsterSpec{}, fmt.Errorf("monster %q should look like NAME[*COUNT]:AC:HP[:DEX]", spec)
+	}
+	m := MonsterSpec{Name: strings.TrimSpace(parts[0]), Count: 1, HP: parts[2], Dex: 10}
+	if name, count, found := strings.Cut(m.Name, "*"); found {
+		n, err := strconv.Atoi(count)
+		if err != nil || n < 1 {
+			return m, fmt.Errorf("monster %q: invalid count %q", spec, count)
+		}
+		m.Name, m.Count = strings.TrimSpace(name), n
+	}
+	if m.Name == "" {
+		return m, fmt.Errorf("monster %q has no name", spec)
+	}
+	var err error
+	if m.AC, err = strconv.Atoi(parts[1]); err != nil || m.AC < 1 {
+		return m, fmt.Errorf("monster %q: invalid AC %q", spec, parts[1])
+	}
+	if _, err := dice.Parse(m.HP); err != nil {
+		return m, fmt.Errorf("monster %q: invalid hit points: %v", spec, err)
+	}
+	if len(parts) == 4 {
+		if m.Dex, err = strconv.Atoi(parts[3]); err != nil || m.Dex < 1 {
+			return m, fmt.Errorf("monster %q: invalid DEX %q", spec, parts[3])
+		}
+	}
+	return m, nil
+}
+
+// Start rolls initiative for the characters and monsters and sorts them into turn order: highest
+// initiative first, ties going to the higher DEX score. Several monsters of a kind are numbered.
+func Start(characters []characterModel.Character, monsters []MonsterSpec, service *characterModel.CharacterService, rng dice.Source) (*Encounter, error) {
+	enc := &Encounter{Round: 1}
+	seen := map[string]bool{}
+	add := func(p Participant, bonus int) error {
+		if seen[strings.ToLower(p.Name)] {
+			return fmt.Errorf("%s is in the encounter twice", p.Name)
+		}
+		seen[strings.ToLower(p.Name)] = true
+		expr := "1d20"
+		if bonus != 0 {
+			expr += fmt.Sprintf("%+d", bonus)
+		}
+		roll, err := dice.Roll(expr, rng)
+		if err != nil {
+			return err
+		}
+		p.Initiative, p.Roll = roll.Total, roll.String()
+		enc.Participants = append(enc.Participants, p)
+		return nil
+	}
+
+	for i := range characters {
+		char := &characters[i]
+		if char.Dead {
+			return nil, fmt.Errorf("%s is dead", char.Name)
+		}
+		if err := add(Participant{Name: char.Name, Dex: char.Dex}, combat.CalculateInitiative(char, service)); err != nil {
+			return nil, err
+		}
+	}
+	for _, m := range monsters {
+		for i := 1; i <= m.Count; i++ {
+			name := m.Name
+			if m.Count > 1 {
+				name = fmt.Sprintf("%s %d", m.Name, i)
+			}
+			hp, err := dice.Roll(m.HP, rng)
+			if err != nil {
+				return nil, err
+			}
+			monster := &characterModel.Character{Name: name, Dex: m.Dex, ArmorClass: m.AC, MaxHP: max(1, hp.Total), CurrentHP: max(1, hp.Total)}
+			if err := add(Participant{Name: name, Dex: m.Dex, Monster: monster}, service.AbilityModifier(m.Dex)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(enc.Participants) == 0 {
+		return nil, fmt.Errorf("an encounter needs at least one participant")
+	}
+
+	sort.SliceStable(enc.Participants, func(i, j int) bool {
+		a, b := enc.Participants[i], enc.Participants[j]
+		if a.Initiative != b.Initiative {
+			return a.Initiative > b.Initiative
+		}
+		return a.Dex > b.Dex
+	})
+	return enc, nil
+}
+
+// Load reads the encounter in progress
+func Load(filename string) (*Encounter, error) {
+	data, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no encounter in progress")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading encounter file: %v", err)
+	}
+	var enc Encounter
+	if err := json.Unmarshal(data, &enc); err != nil {
+		return nil, fmt.Errorf("error parsing encounter file: %v", err)
+	}
+	return &enc, nil
+}
+
+// Save writes the encounter so the next command can pick it up
+func (e *Encounter) Save(filename string) error {
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling encounter: %v", err)
+	}
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("error writing encounter file: %v", err)
+	}
+	return nil
+}
+
+// End removes the saved encounter
+func End(filename string) error {
+	if err := os.Remove(filename); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no encounter in progress")
+		}
+		return fmt.Errorf("error removing encounter file: %v", err)
+	}
+	return nil
+}
+
+// Current returns the participant whose turn it is
+func (e *Encounter) Current() *Participant {
+	return &e.Participants[e.Turn]
+}
+
+// Find returns the participant with the name, ignoring case
+func (e *Encounter) Find(name string) (*Participant, error) {
+	for i := range e.Participants {
+		if strings.EqualFold(e.Participants[i].Name, strings.TrimSpace(name)) {
+			return &e.Participants[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%s isn't in the encounter", name)
+}
+
+// Refresh marks the participants who died since the last command, e.g. from a failed death save,
+// as out of the fight
+func (e *Encounter) Refresh(sheet func(p *Participant) *characterModel.Character) {
+	for i := range e.Participants {
+		if char := sheet(&e.Participants[i]); char != nil {
+			e.Participants[i].Out = char.Dead
+		}
+	}
+}
+
+// Next moves to the next participant still in the fight, starting a new round after the last one.
+// It fails when nobody is left.
+func (e *Encounter) Next() (*Participant, error) {
+	for range e.Participants {
+		e.Turn++
+		if e.Turn == len(e.Participants) {
+			e.Turn = 0
+			e.Round++
+		}
+		if !e.Current().Out {
+			return e.Current(), nil
+		}
+	}
+	return nil, fmt.Errorf("everyone in the encounter is out of the fight")
+}
+
+// ApplyDamage damages the participant, whose sheet is the monster's own or the stored character.
+// Monsters are defeated at 0 hit points instead of making death saves.
+func (p *Participant) ApplyDamage(char *characterModel.Character, amount int, critical bool) (combat.DamageResult, error) {
+	result, err := combat.ApplyDamage(char, amount, critical)
+	if err != nil {
+		return result, err
+	}
+	if p.Monster != nil && char.CurrentHP == 0 {
+		condition.Remove(char, "unconscious", 0)
+		char.Dead = true
+		result.Dying = false
+	}
+	p.Out = char.Dead
+	return result, nil
+}
+
+// Status describes a participant's hit points, conditions and death state for the turn order,
+// given their sheet: the monster's own or the stored character's
+func (p *Participant) Status(char *characterModel.Character) string {
+	switch {
+	case char.Dead && p.Monster != nil:
+		return "defeated"
+	case char.Dead:
+		return "dead"
+	}
+	parts := []string{fmt.Sprintf("HP %d/%d", char.CurrentHP, condition.MaxHP(char))}
+	if char.TempHP > 0 {
+		parts[0] += fmt.Sprintf(" (+%d)", char.TempHP)
+	}
+	if char.ArmorClass > 0 {
+		parts = append(parts, fmt.Sprintf("AC %d", char.ArmorClass))
+	}
+	var conditions []string
+	conditions = append(conditions, char.Conditions...)
+	if char.Exhaustion > 0 {
+		conditions = append(conditions, fmt.Sprintf("exhaustion %d", char.Exhaustion))
+	}
+	if char.Concentration != "" {
+		conditions = append(conditions, "concentrating on "+strings.ToLower(char.Concentration))
+	}
+	if len(conditions) > 0 {
+		parts = append(parts, strings.Join(conditions, ", "))
+	}
+	if state := combat.FormatDeathState(char); state != "" {
+		parts = append(parts, state)
+	}
+	return strings.Join(parts, "  ")
+}
+
+// Format returns the turn order, marking whose turn it is. sheet returns the character sheet
+// for a participant: the monster's own or the stored character's.
+func (e *Encounter) Format(sheet func(p *Participant) *characterModel.Character) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Round %d\n", e.Round))
+	for i := range e.Participants {
+		p := &e.Participants[i]
+		marker := " "
+		if i == e.Turn {
+			marker = ">"
+		}
+		status := "not found"
+		if char := sheet(p); char != nil {
+			status = p.Status(char)
+		}
+		sb.WriteString(fmt.Sprintf("%s %3d  %-20s %s\n", marker, p.Initiative, p.Name, status))
+	}
+	return sb.String()
+}
@@ -0,0 +1,130 @@
+package encounter
+
+import (
+	characterModel "modules/dndcharactersheet/internal/character"
+	"modules/dndcharactersheet/internal/condition"
+	"modules/dndcharactersheet/internal/dice"
+	"testing"
+)
+
+func names(enc *Encounter) []string {
+	var out []string
+	for _, p := range enc.Participants {
+		out = append(out, p.Name)
+	}
+	return out
+}
+
+func TestStartOrder(t *testing.T) {
+	characters := []characterModel.Character{
+		{Name: "Tordek", Dex: 8, MaxHP: 30, CurrentHP: 30},  // -1
+		{Name: "Lidda", Dex: 12, MaxHP: 20, CurrentHP: 20},  // +1
+		{Name: "Mialee", Dex: 16, MaxHP: 15, CurrentHP: 15}, // +3
+	}
+	monsters := []MonsterSpec{{Name: "Orc", Count: 2, AC: 13, HP: "2d8", Dex: 14}} // +2
+	// Tordek 17-1, Lidda 15+1, Mialee 10+3; Orc 1 HP 4+5 and 14+2, Orc 2 HP 3+3 and 11+2
+	rng := dice.Faces(17, 15, 10, 4, 5, 14, 3, 3, 11)
+	enc, err := Start(characters, monsters, characterModel.NewCharacterService(), rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Orc 1", "Lidda", "Tordek", "Mialee", "Orc 2"}
+	got := names(enc)
+	if len(got) != len(want) {
+		t.Fatalf("turn order %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("turn order %v, want %v", got, want)
+		}
+	}
+	if enc.Round != 1 || enc.Turn != 0 {
+		t.Errorf("encounter starts at round %d turn %d", enc.Round, enc.Turn)
+	}
+	orc, _ := enc.Find("orc 2")
+	if orc.Monster == nil || orc.Monster.MaxHP != 6 || orc.Monster.CurrentHP != 6 || orc.Monster.ArmorClass != 13 {
+		t.Errorf("Orc 2 = %+v", orc.Monster)
+	}
+}
+
+func TestStartRejects(t *testing.T) {
+	service := characterModel.NewCharacterService()
+	dead := []characterModel.Character{{Name: "Gone", Dead: true}}
+	if _, err := Start(dead, nil, service, dice.Faces(10)); err == nil {
+		t.Errorf("Start with a dead character succeeded")
+	}
+	twice := []characterModel.Character{{Name: "Lidda"}, {Name: "lidda"}}
+	if _, err := Start(twice, nil, service, dice.Faces(10, 10)); err == nil {
+		t.Errorf("Start with a character twice succeeded")
+	}
+	if _, err := Start(nil, nil, service, dice.Faces()); err == nil {
+		t.Errorf("Start with nobody succeeded")
+	}
+}
+
+func TestNext(t *testing.T) {
+	enc := &Encounter{Round: 1, Participants: []Participant{{Name: "A"}, {Name: "B", Out: true}, {Name: "C"}, {Name: "D", Out: true}}}
+	steps := []struct {
+		name  string
+		round int
+	}{{"C", 1}, {"A", 2}, {"C", 2}, {"A", 3}}
+	for _, step := range steps {
+		p, err := enc.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.Name != step.name || enc.Round != step.round {
+			t.Errorf("Next = %s in round %d, want %s in round %d", p.Name, enc.Round, step.name, step.round)
+		}
+	}
+	enc.Participants[0].Out, enc.Participants[2].Out = true, true
+	if _, err := enc.Next(); err == nil {
+		t.Errorf("Next succeeded with everyone out")
+	}
+}
+
+func TestMonsterDefeated(t *testing.T) {
+	monster := &characterModel.Character{Name: "Goblin", MaxHP: 7, CurrentHP: 7}
+	p := &Participant{Name: "Goblin", Monster: monster}
+	result, err := p.ApplyDamage(monster, 9, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !monster.Dead || !p.Out || result.Dying || condition.Has(monster, "unconscious") {
+		t.Errorf("goblin at 0 hit points: dead %v, out %v, dying %v, conditions %v", monster.Dead, p.Out, result.Dying, monster.Conditions)
+	}
+	if status := p.Status(monster); status != "defeated" {
+		t.Errorf("Status = %q, want defeated", status)
+	}
+
+	char := &characterModel.Character{Name: "Lidda", MaxHP: 20, CurrentHP: 4}
+	hero := &Participant{Name: "Lidda"}
+	result, err = hero.ApplyDamage(char, 9, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if char.Dead || hero.Out || !result.Dying {
+		t.Errorf("character at 0 hit points: dead %v, out %v, dying %v", char.Dead, hero.Out, result.Dying)
+	}
+	if _, err := hero.ApplyDamage(char, 20, false); err != nil || !char.Dead || !hero.Out {
+		t.Errorf("massive damage while dying: dead %v, out %v, %v", char.Dead, hero.Out, err)
+	}
+}
+
+func TestParseMonster(t *testing.T) {
+	m, err := ParseMonster("Goblin*3:15:2d6:14")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m != (MonsterSpec{Name: "Goblin", Count: 3, AC: 15, HP: "2d6", Dex: 14}) {
+		t.Errorf("ParseMonster = %+v", m)
+	}
+	if m, err := ParseMonster("Ogre:11:59"); err != nil || m.Count != 1 || m.Dex != 10 {
+		t.Errorf("ParseMonster(Ogre:11:59) = %+v, %v", m, err)
+	}
+	for _, spec := range []string{"Goblin", "Goblin:15", ":15:7", "Goblin*0:15:7", "Goblin:x:7", "Goblin:15:2x6", "Goblin:15:7:0", "a:1:1:1:1"} {
+		if _, err := ParseMonster(spec); err == nil {
+			t.Errorf("ParseMonster(%q) succeeded", spec)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package equipment
+
+import (
+	"fmt"
+	characterModel "modules/dndcharactersheet/internal/character"
+	"modules/dndcharactersheet/internal/lookup"
+)
+
+// ammunitionByWeapon maps weapons with the ammunition property to the catalog name of their ammunition
+var ammunitionByWeapon = map[string]string{
+	"shortbow":       "arrow",
+	"longbow":        "arrow",
+	"crossbow-light": "crossbow bolt",
+	"crossbow-hand":  "crossbow bolt",
+	"crossbow-heavy": "crossbow bolt",
+	"sling":          "sling bullet",
+	"blowgun":        "blowgun needle",
+}
+
+// AmmunitionFor returns the ammunition a weapon fires, or "" if it doesn't use ammunition
+func AmmunitionFor(weaponName string) string {
+	return ammunitionByWeapon[lookup.Index(weaponName)]
+}
+
+// ConsumeAmmunition spends ammunition for shots fired with the weapon, taking it from the inventory
+// and remembering how much was spent so half can be recovered after the fight
+func ConsumeAmmunition(char *characterModel.Character, weaponName string, shots int) (string, error) {
+	ammo := AmmunitionFor(weaponName)
+	if ammo == "" {
+		return "", fmt.Errorf("%s doesn't use ammunition", weaponName)
+	}
+	if have := CountItem(char, ammo); have < shots {
+		return ammo, fmt.Errorf("out of ammunition: %d %s left", have, ammo)
+	}
+	if err := RemoveItem(char, ammo, shots); err != nil {
+		return ammo, err
+	}
+	if char.SpentAmmunition == nil {
+		char.SpentAmmunition = make(map[string]int)
+	}
+	char.SpentAmmunition[ammo] += shots
+	return ammo, nil
+}
+
+// RecoverAmmunition returns half of the ammunition spent since the last recovery (rounded down) to the inventory
+func RecoverAmmunition(char *characterModel.Character) map[string]int {
+	recovered := make(map[string]int)
+	for ammo, spent := range char.SpentAmmunition {
+		if n := spent / 2; n > 0 {
+			_ = AddItem(char, ammo, n)
+			recovered[ammo] = n
+		}
+	}
+	char.SpentAmmunition = nil
+	return recovered
+}
@@ -0,0 +1,138 @@
+package equipment
+
+import (
+	"fmt"
+	"math"
+	"modules/dndcharactersheet/internal/api"
+	characterModel "modules/dndcharactersheet/internal/character"
+	"modules/dndcharactersheet/internal/currency"
+	"modules/dndcharactersheet/internal/lookup"
+	"strconv"
+	"strings"
+)
+
+// AddItem adds a quantity of an item to the character's inventory, stacking with items of the same name
+func AddItem(char *characterModel.Character, name string, quantity int) error {
+	if quantity <= 0 {
+		return fmt.Errorf("quantity must be positive")
+	}
+	name = strings.ToLower(strings.TrimSpace(name))
+	for i, it := range char.Inventory {
+		if lookup.Matches(it.Name, name) {
+			char.Inventory[i].Quantity += quantity
+			return nil
+		}
+	}
+	char.Inventory = append(char.Inventory, characterModel.InventoryItem{Name: name, Quantity: quantity})
+	return nil
+}
+
+// RemoveItem takes a quantity of an item out of the inventory, dropping the stack when it runs out
+func RemoveItem(char *characterModel.Character, name string, quantity int) error {
+	if quantity <= 0 {
+		return fmt.Errorf("quantity must be positive")
+	}
+	name = strings.ToLower(strings.TrimSpace(name))
+	for i, it := range char.Inventory {
+		if !lookup.Matches(it.Name, name) {
+			continue
+		}
+		if it.Quantity < quantity {
+			return fmt.Errorf("only %d %s in inventory", it.Quantity, it.Name)
+		}
+		char.Inventory[i].Quantity -= quantity
+		if char.Inventory[i].Quantity == 0 {
+			char.Inventory = append(char.Inventory[:i], char.Inventory[i+1:]...)
+		}
+		return nil
+	}
+	return fmt.Errorf("%s not in inventory", name)
+}
+
+// CountItem returns how many of an item the character carries in the inventory
+func CountItem(char *characterModel.Character, name string) int {
+	for _, it := range char.Inventory {
+		if lookup.Matches(it.Name, name) {
+			return it.Quantity
+		}
+	}
+	return 0
+}
+
+// ItemWeight returns the weight in pounds of a single item from the catalog (bundles are divided by their size)
+func ItemWeight(items []EquipmentItem, name string) float64 {
+	item := FindEquipmentByName(items, name)
+	if item == nil || item.Quantity == 0 {
+		return 0
+	}
+	return item.Weight / float64(item.Quantity)
+}
+
+// CarriedWeight returns the total weight of the inventory plus equipped weapons, armor and shield
+func CarriedWeight(char *characterModel.Character, items []EquipmentItem) float64 {
+	total := 0.0
+	for _, it := range char.Inventory {
+		total += ItemWeight(items, it.Name) * float64(it.Quantity)
+	}
+	for _, equipped := range []string{char.MainHand, char.OffHand, char.Armor, char.Shield} {
+		if equipped != "" {
+			total += ItemWeight(items, equipped)
+		}
+	}
+	return math.Round(total*100) / 100
+}
+
+// FormatInventory returns a formatted string listing the inventory with item weights
+func FormatInventory(char *characterModel.Character, items []EquipmentItem) string {
+	if len(char.Inventory) == 0 {
+		return "Inventory: empty\n"
+	}
+	var sb strings.Builder
+	sb.WriteString("Inventory:\n")
+	for _, it := range char.Inventory {
+		weight := ItemWeight(items, it.Name) * float64(it.Quantity)
+		sb.WriteString(fmt.Sprintf("  %dx %s (%s lb)\n", it.Quantity, it.Name, FormatWeight(weight)))
+	}
+	return sb.String()
+}
+
+// FormatWeight formats a weight in pounds with at most two decimals
+func FormatWeight(weight float64) string {
+	return strconv.FormatFloat(math.Round(weight*100)/100, 'f', -1, 64)
+}
+
+// UnitCost returns the price in copper of a single item, from the catalog or API enrichment as a fallback.
+// Bundled items like arrows are priced per piece, rounded up.
+func UnitCost(items []EquipmentItem, name string) (int, bool) {
+	item := FindEquipmentByName(items, name)
+	if item != nil && item.Cost > 0 {
+		return ceilDiv(item.Cost, item.Quantity), true
+	}
+	enriched, err := api.GetEquipment(lookup.Index(name))
+	if err != nil || enriched == nil || enriched.Cost.Quantity == 0 {
+		return 0, false
+	}
+	bundle := enriched.Quantity
+	if bundle == 0 {
+		bundle = 1
+	}
+	return ceilDiv(enriched.Cost.Quantity*currency.UnitValue(enriched.Cost.Unit), bundle), true
+}
+
+// PriceOf returns the copper price for a quantity of an item, keeping bundle pricing exact
+func PriceOf(items []EquipmentItem, name string, quantity int) (int, bool) {
+	item := FindEquipmentByName(items, name)
+	if item != nil && item.Cost > 0 {
+		return ceilDiv(item.Cost*quantity, item.Quantity), true
+	}
+	unit, ok := UnitCost(items, name)
+	return unit * quantity, ok
+}
+
+// ceilDiv divides a by b rounding up, treating b <= 0 as 1
+func ceilDiv(a, b int) int {
+	if b <= 0 {
+		b = 1
+	}
+	return (a + b - 1) / b
+}
@@ -2,5 +2,8 @@ package equipment
 
 type EquipmentItem struct {
 	Name     string
-	Category string // e.g., Weapon, Armor, Shield
+	Category string  // e.g., Weapon, Armor, Shield
+	Weight   float64 // in pounds, for the whole bundle
+	Quantity int     // items per bundle, e.g. 20 arrows
+	Cost     int     // in copper pieces, for the whole bundle
 }
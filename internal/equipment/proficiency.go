@@ -0,0 +1,44 @@
+package equipment
+
+import (
+	characterModel "modules/dndcharactersheet/internal/character"
+	"modules/dndcharactersheet/internal/lookup"
+	"strings"
+)
+
+// IsWeaponProficient reports whether the character is proficient with the named weapon,
+// either through its category ("simple", "martial") or the specific weapon.
+// Weapons missing from the SRD table are treated as proficient.
+func IsWeaponProficient(char *characterModel.Character, name string) bool {
+	stats, found := LookupWeapon(name)
+	if !found {
+		return true
+	}
+	idx := lookup.Index(name)
+	for _, p := range char.WeaponProficiencies {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if p == stats.Category || lookup.Index(p) == idx {
+			return true
+		}
+	}
+	return false
+}
+
+// IsArmorProficient reports whether the character is proficient with the named armor or shield.
+// Armor missing from the SRD table is treated as proficient.
+func IsArmorProficient(char *characterModel.Character, name string) bool {
+	stats, found := LookupArmor(name)
+	if !found {
+		return true
+	}
+	category := stats.Category
+	if category == "shield" {
+		category = "shields"
+	}
+	for _, p := range char.ArmorProficiencies {
+		if strings.EqualFold(strings.TrimSpace(p), category) {
+			return true
+		}
+	}
+	return false
+}
@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"modules/dndcharactersheet/internal/api"
 	characterModel "modules/dndcharactersheet/internal/character"
+	"modules/dndcharactersheet/internal/currency"
+	"modules/dndcharactersheet/internal/lookup"
 	"os"
+	"strconv"
 	"strings"
 )
 
@@ -21,7 +24,7 @@ func GetFormattedEquipment(char *characterModel.Character) EquipmentDisplay {
 	var disp EquipmentDisplay
 	// Main hand
 	if char.MainHand != "" {
-		idx := api.ToAPIIndex(char.MainHand)
+		idx := lookup.Index(char.MainHand)
 		weapon, err := api.GetWeapon(idx)
 		var mainHandName string
 		if err == nil && weapon != nil {
@@ -33,7 +36,7 @@ func GetFormattedEquipment(char *characterModel.Character) EquipmentDisplay {
 	}
 	// Off hand
 	if char.OffHand != "" {
-		idx := api.ToAPIIndex(char.OffHand)
+		idx := lookup.Index(char.OffHand)
 		weapon, err := api.GetWeapon(idx)
 		var offHandName string
 		if err == nil && weapon != nil {
@@ -45,7 +48,7 @@ func GetFormattedEquipment(char *characterModel.Character) EquipmentDisplay {
 	}
 	// Armor
 	if char.Armor != "" {
-		idx := api.ToAPIIndex(char.Armor)
+		idx := lookup.Index(char.Armor)
 		armor, err := api.GetArmor(idx)
 		var armorName string
 		if err == nil && armor != nil {
@@ -57,7 +60,7 @@ func GetFormattedEquipment(char *characterModel.Character) EquipmentDisplay {
 	}
 
 	if char.Shield != "" {
-		idx := api.ToAPIIndex(char.Shield)
+		idx := lookup.Index(char.Shield)
 		shield, err := api.GetArmor(idx)
 		var shieldName string
 		if err == nil && shield != nil {
@@ -112,6 +115,16 @@ func LoadEquipmentFromCSV(filename string) ([]EquipmentItem, error) {
 		if item.Category == "" {
 			item.Category = get("category")
 		}
+		if w, err := strconv.ParseFloat(get("weight"), 64); err == nil {
+			item.Weight = w
+		}
+		item.Quantity = 1
+		if q, err := strconv.Atoi(get("quantity")); err == nil && q > 0 {
+			item.Quantity = q
+		}
+		if c, err := currency.ParseCost(get("cost")); err == nil {
+			item.Cost = c
+		}
 
 		items = append(items, item)
 	}
@@ -119,13 +132,22 @@ func LoadEquipmentFromCSV(filename string) ([]EquipmentItem, error) {
 	return items, nil
 }
 
-// FindEquipmentByName finds an equipment item by name (case-insensitive)
+// FindEquipmentByName finds an equipment item by name, ignoring case, punctuation and an "armor"
+// suffix and going through the alias table, so "Leather" finds "Leather Armor"
 func FindEquipmentByName(items []EquipmentItem, name string) *EquipmentItem {
-	name = strings.ToLower(strings.TrimSpace(name))
 	for _, it := range items {
-		if strings.ToLower(it.Name) == name {
+		if lookup.Matches(it.Name, name) {
 			return &it
 		}
 	}
 	return nil
 }
+
+// SuggestEquipment returns the names of the catalog items closest to a name that wasn't found
+func SuggestEquipment(items []EquipmentItem, name string) []string {
+	names := make([]string, 0, len(items))
+	for _, it := range items {
+		names = append(names, it.Name)
+	}
+	return lookup.Suggest(name, names, 3)
+}
@@ -0,0 +1,156 @@
+package equipment
+
+import (
+	"fmt"
+	characterModel "modules/dndcharactersheet/internal/character"
+	"strings"
+)
+
+// Equipment slots on a character
+const (
+	SlotMainHand = "main hand"
+	SlotOffHand  = "off hand"
+	SlotArmor    = "armor"
+	SlotShield   = "shield"
+)
+
+// NormalizeSlot maps user input like "mh" or "off" to a slot name, or "" if it isn't a slot
+func NormalizeSlot(slot string) string {
+	switch strings.ToLower(strings.TrimSpace(slot)) {
+	case "main hand", "main", "mh", "mainhand":
+		return SlotMainHand
+	case "off hand", "off", "oh", "offhand":
+		return SlotOffHand
+	case "armor", "armour", "body":
+		return SlotArmor
+	case "shield":
+		return SlotShield
+	default:
+		return ""
+	}
+}
+
+// isTwoHanded reports whether the named weapon needs both hands
+func isTwoHanded(name string) bool {
+	if name == "" {
+		return false
+	}
+	stats, _ := LookupWeapon(name)
+	return stats.HasProperty("two-handed")
+}
+
+// CanEquip checks that the item fits the slot: the slot must be free, two-handed weapons need
+// both hands and no shield, and a shield and an off-hand weapon can't be held together.
+func CanEquip(char *characterModel.Character, slot string, itemName string) error {
+	switch slot {
+	case SlotMainHand:
+		if char.MainHand != "" {
+			return fmt.Errorf("%s already occupied", slot)
+		}
+		if isTwoHanded(itemName) {
+			if char.OffHand != "" {
+				return fmt.Errorf("%s is two-handed but the off hand holds %s", itemName, char.OffHand)
+			}
+			if char.Shield != "" {
+				return fmt.Errorf("%s is two-handed but a shield is equipped", itemName)
+			}
+		}
+	case SlotOffHand:
+		if char.OffHand != "" {
+			return fmt.Errorf("%s already occupied", slot)
+		}
+		if isTwoHanded(itemName) {
+			return fmt.Errorf("%s is two-handed and must go in the main hand", itemName)
+		}
+		if isTwoHanded(char.MainHand) {
+			return fmt.Errorf("the main hand holds two-handed %s", char.MainHand)
+		}
+		if char.Shield != "" {
+			return fmt.Errorf("a shield is equipped in the off hand")
+		}
+	case SlotArmor:
+		if char.Armor != "" {
+			return fmt.Errorf("armor already occupied")
+		}
+	case SlotShield:
+		if char.Shield != "" {
+			return fmt.Errorf("shield already occupied")
+		}
+		if char.OffHand != "" {
+			return fmt.Errorf("the off hand holds %s", char.OffHand)
+		}
+		if isTwoHanded(char.MainHand) {
+			return fmt.Errorf("the main hand holds two-handed %s", char.MainHand)
+		}
+	default:
+		return fmt.Errorf("unknown slot %q", slot)
+	}
+	return nil
+}
+
+// Equip puts the item in the slot after checking it fits
+func Equip(char *characterModel.Character, slot string, itemName string) error {
+	if err := CanEquip(char, slot, itemName); err != nil {
+		return err
+	}
+	itemName = strings.ToLower(itemName)
+	switch slot {
+	case SlotMainHand:
+		char.MainHand = itemName
+	case SlotOffHand:
+		char.OffHand = itemName
+	case SlotArmor:
+		char.Armor = itemName
+	case SlotShield:
+		char.Shield = itemName
+	}
+	return nil
+}
+
+// Unequip empties the slot and returns the item that was in it
+func Unequip(char *characterModel.Character, slot string) (string, error) {
+	var removed string
+	switch slot {
+	case SlotMainHand:
+		removed, char.MainHand = char.MainHand, ""
+	case SlotOffHand:
+		removed, char.OffHand = char.OffHand, ""
+	case SlotArmor:
+		removed, char.Armor = char.Armor, ""
+	case SlotShield:
+		removed, char.Shield = char.Shield, ""
+	default:
+		return "", fmt.Errorf("unknown slot %q", slot)
+	}
+	if removed == "" {
+		return "", fmt.Errorf("%s is empty", slot)
+	}
+	return removed, nil
+}
+
+// Replace swaps the item in the slot for a new one, restoring the old item if the new one doesn't fit
+func Replace(char *characterModel.Character, slot string, itemName string) (string, error) {
+	removed, _ := Unequip(char, slot)
+	if err := Equip(char, slot, itemName); err != nil {
+		if removed != "" {
+			_ = Equip(char, slot, removed)
+		}
+		return "", err
+	}
+	return removed, nil
+}
+
+// SwapHands exchanges the main hand and off hand weapons
+func SwapHands(char *characterModel.Character) error {
+	if char.MainHand == "" && char.OffHand == "" {
+		return fmt.Errorf("no weapons equipped")
+	}
+	if isTwoHanded(char.MainHand) {
+		return fmt.Errorf("%s is two-handed and must stay in the main hand", char.MainHand)
+	}
+	if char.MainHand != "" && char.Shield != "" {
+		return fmt.Errorf("a shield is equipped in the off hand")
+	}
+	char.MainHand, char.OffHand = char.OffHand, char.MainHand
+	return nil
+}
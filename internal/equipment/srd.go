@@ -0,0 +1,148 @@
+package equipment
+
+import (
+	"modules/dndcharactersheet/internal/api"
+	"modules/dndcharactersheet/internal/lookup"
+	"strings"
+)
+
+// WeaponStats holds the SRD weapon table values used for attacks and proficiency checks
+type WeaponStats struct {
+	Name       string
+	Category   string // "simple" or "martial"
+	Ranged     bool
+	Damage     string // damage dice, e.g. "1d8"
+	DamageType string
+	Properties []string // lowercase SRD property names, e.g. "finesse", "two-handed"
+}
+
+// HasProperty reports whether the weapon has the given SRD property
+func (w WeaponStats) HasProperty(property string) bool {
+	for _, p := range w.Properties {
+		if strings.EqualFold(p, property) {
+			return true
+		}
+	}
+	return false
+}
+
+// ArmorStats holds the SRD armor table values
+type ArmorStats struct {
+	Name                string
+	Category            string // "light", "medium", "heavy" or "shield"
+	Base                int    // base AC, or the AC bonus for shields
+	DexBonus            bool
+	MaxBonus            int // cap on the dexterity bonus, 0 means uncapped
+	StrMinimum          int // wearing it with a lower STR score reduces speed by 10 feet
+	StealthDisadvantage bool
+}
+
+// srdWeapons is the offline fallback for weapon data, keyed by API index
+var srdWeapons = map[string]WeaponStats{
+	// Simple melee
+	"club":         {Name: "Club", Category: "simple", Damage: "1d4", DamageType: "bludgeoning", Properties: []string{"light"}},
+	"dagger":       {Name: "Dagger", Category: "simple", Damage: "1d4", DamageType: "piercing", Properties: []string{"finesse", "light", "thrown"}},
+	"greatclub":    {Name: "Greatclub", Category: "simple", Damage: "1d8", DamageType: "bludgeoning", Properties: []string{"two-handed"}},
+	"handaxe":      {Name: "Handaxe", Category: "simple", Damage: "1d6", DamageType: "slashing", Properties: []string{"light", "thrown"}},
+	"javelin":      {Name: "Javelin", Category: "simple", Damage: "1d6", DamageType: "piercing", Properties: []string{"thrown"}},
+	"light-hammer": {Name: "Light hammer", Category: "simple", Damage: "1d4", DamageType: "bludgeoning", Properties: []string{"light", "thrown"}},
+	"mace":         {Name: "Mace", Category: "simple", Damage: "1d6", DamageType: "bludgeoning"},
+	"quarterstaff": {Name: "Quarterstaff", Category: "simple", Damage: "1d6", DamageType: "bludgeoning", Properties: []string{"versatile"}},
+	"sickle":       {Name: "Sickle", Category: "simple", Damage: "1d4", DamageType: "slashing", Properties: []string{"light"}},
+	"spear":        {Name: "Spear", Category: "simple", Damage: "1d6", DamageType: "piercing", Properties: []string{"thrown", "versatile"}},
+	// Simple ranged
+	"crossbow-light": {Name: "Crossbow, light", Category: "simple", Ranged: true, Damage: "1d8", DamageType: "piercing", Properties: []string{"ammunition", "loading", "two-handed"}},
+	"dart":           {Name: "Dart", Category: "simple", Ranged: true, Damage: "1d4", DamageType: "piercing", Properties: []string{"finesse", "thrown"}},
+	"shortbow":       {Name: "Shortbow", Category: "simple", Ranged: true, Damage: "1d6", DamageType: "piercing", Properties: []string{"ammunition", "two-handed"}},
+	"sling":          {Name: "Sling", Category: "simple", Ranged: true, Damage: "1d4", DamageType: "bludgeoning", Properties: []string{"ammunition"}},
+	// Martial melee
+	"battleaxe":   {Name: "Battleaxe", Category: "martial", Damage: "1d8", DamageType: "slashing", Properties: []string{"versatile"}},
+	"flail":       {Name: "Flail", Category: "martial", Damage: "1d8", DamageType: "bludgeoning"},
+	"glaive":      {Name: "Glaive", Category: "martial", Damage: "1d10", DamageType: "slashing", Properties: []string{"heavy", "reach", "two-handed"}},
+	"greataxe":    {Name: "Greataxe", Category: "martial", Damage: "1d12", DamageType: "slashing", Properties: []string{"heavy", "two-handed"}},
+	"greatsword":  {Name: "Greatsword", Category: "martial", Damage: "2d6", DamageType: "slashing", Properties: []string{"heavy", "two-handed"}},
+	"halberd":     {Name: "Halberd", Category: "martial", Damage: "1d10", DamageType: "slashing", Properties: []string{"heavy", "reach", "two-handed"}},
+	"lance":       {Name: "Lance", Category: "martial", Damage: "1d12", DamageType: "piercing", Properties: []string{"reach", "special"}},
+	"longsword":   {Name: "Longsword", Category: "martial", Damage: "1d8", DamageType: "slashing", Properties: []string{"versatile"}},
+	"maul":        {Name: "Maul", Category: "martial", Damage: "2d6", DamageType: "bludgeoning", Properties: []string{"heavy", "two-handed"}},
+	"morningstar": {Name: "Morningstar", Category: "martial", Damage: "1d8", DamageType: "piercing"},
+	"pike":        {Name: "Pike", Category: "martial", Damage: "1d10", DamageType: "piercing", Properties: []string{"heavy", "reach", "two-handed"}},
+	"rapier":      {Name: "Rapier", Category: "martial", Damage: "1d8", DamageType: "piercing", Properties: []string{"finesse"}},
+	"scimitar":    {Name: "Scimitar", Category: "martial", Damage: "1d6", DamageType: "slashing", Properties: []string{"finesse", "light"}},
+	"shortsword":  {Name: "Shortsword", Category: "martial", Damage: "1d6", DamageType: "piercing", Properties: []string{"finesse", "light"}},
+	"trident":     {Name: "Trident", Category: "martial", Damage: "1d6", DamageType: "piercing", Properties: []string{"thrown", "versatile"}},
+	"war-pick":    {Name: "War pick", Category: "martial", Damage: "1d8", DamageType: "piercing"},
+	"warhammer":   {Name: "Warhammer", Category: "martial", Damage: "1d8", DamageType: "bludgeoning", Properties: []string{"versatile"}},
+	"whip":        {Name: "Whip", Category: "martial", Damage: "1d4", DamageType: "slashing", Properties: []string{"finesse", "reach"}},
+	// Martial ranged
+	"blowgun":        {Name: "Blowgun", Category: "martial", Ranged: true, Damage: "1", DamageType: "piercing", Properties: []string{"ammunition", "loading"}},
+	"crossbow-hand":  {Name: "Crossbow, hand", Category: "martial", Ranged: true, Damage: "1d6", DamageType: "piercing", Properties: []string{"ammunition", "light", "loading"}},
+	"crossbow-heavy": {Name: "Crossbow, heavy", Category: "martial", Ranged: true, Damage: "1d10", DamageType: "piercing", Properties: []string{"ammunition", "heavy", "loading", "two-handed"}},
+	"longbow":        {Name: "Longbow", Category: "martial", Ranged: true, Damage: "1d8", DamageType: "piercing", Properties: []string{"ammunition", "heavy", "two-handed"}},
+	"net":            {Name: "Net", Category: "martial", Ranged: true, Properties: []string{"special", "thrown"}},
+}
+
+// srdArmor is the offline fallback for armor data, keyed by API index
+var srdArmor = map[string]ArmorStats{
+	"padded-armor":          {Name: "Padded Armor", Category: "light", Base: 11, DexBonus: true, StealthDisadvantage: true},
+	"leather-armor":         {Name: "Leather Armor", Category: "light", Base: 11, DexBonus: true},
+	"studded-leather-armor": {Name: "Studded Leather Armor", Category: "light", Base: 12, DexBonus: true},
+	"hide-armor":            {Name: "Hide Armor", Category: "medium", Base: 12, DexBonus: true, MaxBonus: 2},
+	"chain-shirt":           {Name: "Chain Shirt", Category: "medium", Base: 13, DexBonus: true, MaxBonus: 2},
+	"scale-mail":            {Name: "Scale Mail", Category: "medium", Base: 14, DexBonus: true, MaxBonus: 2, StealthDisadvantage: true},
+	"breastplate":           {Name: "Breastplate", Category: "medium", Base: 14, DexBonus: true, MaxBonus: 2},
+	"half-plate-armor":      {Name: "Half Plate Armor", Category: "medium", Base: 15, DexBonus: true, MaxBonus: 2, StealthDisadvantage: true},
+	"ring-mail":             {Name: "Ring Mail", Category: "heavy", Base: 14, StealthDisadvantage: true},
+	"chain-mail":            {Name: "Chain Mail", Category: "heavy", Base: 16, StrMinimum: 13, StealthDisadvantage: true},
+	"splint-armor":          {Name: "Splint Armor", Category: "heavy", Base: 17, StrMinimum: 15, StealthDisadvantage: true},
+	"plate-armor":           {Name: "Plate Armor", Category: "heavy", Base: 18, StrMinimum: 15, StealthDisadvantage: true},
+	"shield":                {Name: "Shield", Category: "shield", Base: 2},
+}
+
+// LookupWeapon returns weapon stats, enriched from the API when possible and the SRD table otherwise
+func LookupWeapon(name string) (WeaponStats, bool) {
+	idx := lookup.Index(name)
+	stats, found := srdWeapons[idx]
+	weapon, err := api.GetWeapon(idx)
+	if err == nil && weapon != nil && weapon.Category != "" {
+		stats.Name = weapon.Name
+		stats.Category = strings.ToLower(weapon.Category)
+		stats.Ranged = strings.EqualFold(weapon.WeaponRange, "ranged")
+		if weapon.Damage.DamageDice != "" {
+			stats.Damage = weapon.Damage.DamageDice
+			stats.DamageType = strings.ToLower(weapon.Damage.DamageType.Name)
+		}
+		if len(weapon.Properties) > 0 {
+			stats.Properties = nil
+			for _, p := range weapon.Properties {
+				stats.Properties = append(stats.Properties, strings.ToLower(p.Name))
+			}
+		}
+		return stats, true
+	}
+	return stats, found
+}
+
+// LookupArmor returns armor stats, enriched from the API when possible and the SRD table otherwise
+func LookupArmor(name string) (ArmorStats, bool) {
+	idx := lookup.Index(name)
+	if _, ok := srdArmor[idx]; !ok {
+		// the SRD spells some armor with an "armor" suffix: "Plate" is "plate-armor"
+		if _, ok := srdArmor[idx+"-armor"]; ok {
+			idx += "-armor"
+		}
+	}
+	stats, found := srdArmor[idx]
+	armor, err := api.GetArmor(idx)
+	if err == nil && armor != nil && armor.ArmorCategory != "" {
+		stats.Name = armor.Name
+		stats.Category = strings.ToLower(armor.ArmorCategory)
+		stats.Base = armor.ArmorClass.Base
+		stats.DexBonus = armor.ArmorClass.DexBonus
+		stats.MaxBonus = armor.ArmorClass.MaxBonus
+		stats.StrMinimum = armor.StrMinimum
+		stats.StealthDisadvantage = armor.StealthDisadvantage
+		return stats, true
+	}
+	return stats, found
+}
@@ -0,0 +1,174 @@
+package equipment
+
+import (
+	"fmt"
+	backgroundModel "modules/dndcharactersheet/internal/background"
+	characterModel "modules/dndcharactersheet/internal/character"
+	classModel "modules/dndcharactersheet/internal/class"
+	"strings"
+)
+
+// Chooser makes the player's picks while resolving starting equipment. Choose returns the index
+// of the option taken for choice n, Weapon names the weapon that fills a placeholder such as
+// "any simple weapon".
+type Chooser interface {
+	Choose(n int, options [][]classModel.StartingItem) (int, error)
+	Weapon(placeholder string) (string, error)
+}
+
+// IsWeaponPlaceholder reports whether the starting item stands for a weapon the player picks
+func IsWeaponPlaceholder(name string) bool {
+	name = strings.ToLower(name)
+	return strings.HasPrefix(name, "any ") && strings.HasSuffix(name, " weapon")
+}
+
+// MatchesWeaponPlaceholder checks that the weapon fits a placeholder like "any martial melee weapon"
+func MatchesWeaponPlaceholder(placeholder string, weapon string) error {
+	stats, ok := LookupWeapon(weapon)
+	if !ok {
+		return fmt.Errorf("unknown weapon %q", weapon)
+	}
+	kind := strings.TrimSuffix(strings.TrimPrefix(strings.ToLower(placeholder), "any "), " weapon")
+	for _, word := range strings.Fields(kind) {
+		switch word {
+		case "simple", "martial":
+			if stats.Category != word {
+				return fmt.Errorf("%s is not a %s weapon", weapon, word)
+			}
+		case "melee":
+			if stats.Ranged {
+				return fmt.Errorf("%s is not a melee weapon", weapon)
+			}
+		case "ranged":
+			if !stats.Ranged {
+				return fmt.Errorf("%s is not a ranged weapon", weapon)
+			}
+		}
+	}
+	return nil
+}
+
+// OptionLabel returns the letter for the nth option of a choice, "a", "b", ...
+func OptionLabel(n int) string {
+	return string(rune('a' + n))
+}
+
+// ParseOptionLabel returns the option index for a letter like "b", or -1 if it isn't one
+func ParseOptionLabel(label string) int {
+	label = strings.ToLower(strings.Trim(strings.TrimSpace(label), "()"))
+	if len(label) != 1 || label[0] < 'a' || label[0] > 'z' {
+		return -1
+	}
+	return int(label[0] - 'a')
+}
+
+// FormatOption returns an option bundle as text, e.g. "2x handaxe" or "leather armor, longbow, 20x arrow"
+func FormatOption(option []classModel.StartingItem) string {
+	parts := make([]string, 0, len(option))
+	for _, it := range option {
+		if it.Quantity > 1 {
+			parts = append(parts, fmt.Sprintf("%dx %s", it.Quantity, strings.ToLower(it.Name)))
+		} else {
+			parts = append(parts, strings.ToLower(it.Name))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ResolveStartingEquipment returns the class's fixed items, the options picked for each choice and the
+// background's items, with weapon placeholders replaced by the chooser's picks as they come up
+func ResolveStartingEquipment(class classModel.Class, background backgroundModel.Background, chooser Chooser) ([]classModel.StartingItem, error) {
+	resolved, err := resolvePlaceholders(class.StartingEquipment.Items, chooser)
+	if err != nil {
+		return nil, err
+	}
+	for n, options := range class.StartingEquipment.Choices {
+		pick, err := chooser.Choose(n, options)
+		if err != nil {
+			return nil, err
+		}
+		if pick < 0 || pick >= len(options) {
+			return nil, fmt.Errorf("choice %d has no option %s", n+1, OptionLabel(pick))
+		}
+		items, err := resolvePlaceholders(options[pick], chooser)
+		if err != nil {
+			return nil, err
+		}
+		resolved = append(resolved, items...)
+	}
+	return append(resolved, background.Equipment...), nil
+}
+
+// resolvePlaceholders replaces each "any ... weapon" item with weapons picked by the chooser
+func resolvePlaceholders(items []classModel.StartingItem, chooser Chooser) ([]classModel.StartingItem, error) {
+	var resolved []classModel.StartingItem
+	for _, it := range items {
+		if !IsWeaponPlaceholder(it.Name) {
+			resolved = append(resolved, it)
+			continue
+		}
+		for i := 0; i < max(it.Quantity, 1); i++ {
+			weapon, err := chooser.Weapon(it.Name)
+			if err != nil {
+				return nil, err
+			}
+			if err := MatchesWeaponPlaceholder(it.Name, weapon); err != nil {
+				return nil, err
+			}
+			resolved = append(resolved, classModel.StartingItem{Name: weapon})
+		}
+	}
+	return resolved, nil
+}
+
+// startingSlot returns the free slot a starting item goes into, or "" when it belongs in the pack.
+// Armor and shields need proficiency, and a second weapon only goes in the off hand when both are light.
+func startingSlot(char *characterModel.Character, entry *EquipmentItem) string {
+	switch entry.Category {
+	case "Weapon":
+		if !IsWeaponProficient(char, entry.Name) {
+			return ""
+		}
+		if CanEquip(char, SlotMainHand, entry.Name) == nil {
+			return SlotMainHand
+		}
+		main, _ := LookupWeapon(char.MainHand)
+		off, _ := LookupWeapon(entry.Name)
+		if main.HasProperty("light") && off.HasProperty("light") && CanEquip(char, SlotOffHand, entry.Name) == nil {
+			return SlotOffHand
+		}
+	case "Armor":
+		slot := SlotArmor
+		if stats, _ := LookupArmor(entry.Name); stats.Category == "shield" {
+			slot = SlotShield
+		}
+		if IsArmorProficient(char, entry.Name) && CanEquip(char, slot, entry.Name) == nil {
+			return slot
+		}
+	}
+	return ""
+}
+
+// GrantStartingEquipment equips the starting armor, shield and weapons the character can use in
+// free slots and stashes everything else in the inventory. Every item must be in the catalog.
+func GrantStartingEquipment(char *characterModel.Character, items []classModel.StartingItem, catalog []EquipmentItem) error {
+	for _, it := range items {
+		entry := FindEquipmentByName(catalog, it.Name)
+		if entry == nil {
+			return fmt.Errorf("%s is not in the equipment catalog", it.Name)
+		}
+		quantity := max(it.Quantity, 1)
+		if slot := startingSlot(char, entry); slot != "" {
+			if err := Equip(char, slot, entry.Name); err != nil {
+				return err
+			}
+			quantity--
+		}
+		if quantity > 0 {
+			if err := AddItem(char, entry.Name, quantity); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package lookup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"modules/dndcharactersheet/internal/api"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// AliasFile is where the alias table is persisted
+const AliasFile = "aliases.json"
+
+// defaultAliases maps names used by the CSV files and older characters to their API index.
+// Entries in the alias file are added on top of these.
+var defaultAliases = map[string]string{
+	"padded":          "padded-armor",
+	"leather":         "leather-armor",
+	"studded-leather": "studded-leather-armor",
+	"hide":            "hide-armor",
+	"half-plate":      "half-plate-armor",
+	"splint":          "splint-armor",
+	"plate":           "plate-armor",
+	"light-crossbow":  "crossbow-light",
+	"hand-crossbow":   "crossbow-hand",
+	"heavy-crossbow":  "crossbow-heavy",
+	"bolt":            "crossbow-bolt",
+}
+
+var (
+	aliasOnce sync.Once
+	aliases   map[string]string
+	aliasErr  error
+)
+
+// Aliases returns the alias table, loading the alias file on first use. When the file can't be
+// read the built-in aliases are still returned along with the error.
+func Aliases() (map[string]string, error) {
+	aliasOnce.Do(func() {
+		aliases = make(map[string]string, len(defaultAliases))
+		for k, v := range defaultAliases {
+			aliases[k] = v
+		}
+		stored, err := LoadAliases(AliasFile)
+		if err != nil {
+			aliasErr = fmt.Errorf("could not load %s: %v", AliasFile, err)
+		}
+		for k, v := range stored {
+			aliases[k] = v
+		}
+	})
+	return aliases, aliasErr
+}
+
+// LoadAliases reads an alias table from a JSON file; a missing file is an empty table
+func LoadAliases(filename string) (map[string]string, error) {
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	stored := map[string]string{}
+	err = json.Unmarshal(data, &stored)
+	return stored, err
+}
+
+// AddAlias maps a name to an API index and persists it in the alias file
+func AddAlias(name string, index string) error {
+	stored, err := LoadAliases(AliasFile)
+	if err != nil {
+		return err
+	}
+	key, idx := api.ToAPIIndex(name), api.ToAPIIndex(index)
+	if key == "" || idx == "" {
+		return fmt.Errorf("alias name and index are required")
+	}
+	if key == idx {
+		return fmt.Errorf("%s already resolves to %s", name, idx)
+	}
+	stored[key] = idx
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(AliasFile, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	table, _ := Aliases()
+	table[key] = idx
+	return nil
+}
+
+// Index converts a name to its API index, normalizing SRD naming quirks and applying the alias table
+func Index(name string) string {
+	idx := api.ToAPIIndex(name)
+	// a broken alias file is reported once by the command, lookups fall back to the built-in aliases
+	table, _ := Aliases()
+	if alias, ok := table[idx]; ok {
+		return alias
+	}
+	return idx
+}
+
+// Key returns the form two names are compared in: the API index without an "armor" suffix,
+// so "Leather", "leather armor" and "Leather Armor" all match
+func Key(name string) string {
+	return strings.TrimSuffix(Index(name), "-armor")
+}
+
+// Matches reports whether two names refer to the same thing
+func Matches(a string, b string) bool {
+	return Key(a) == Key(b)
+}
+
+// Suggest returns up to limit candidates closest to the name by edit distance,
+// leaving out those too different to be a typo
+func Suggest(name string, candidates []string, limit int) []string {
+	key := Key(name)
+	type scored struct {
+		name     string
+		distance int
+	}
+	var near []scored
+	seen := map[string]bool{}
+	for _, c := range candidates {
+		ck := Key(c)
+		if seen[ck] {
+			continue
+		}
+		seen[ck] = true
+		d := Distance(key, ck)
+		if len(key) >= 3 && strings.HasPrefix(ck, key) {
+			d = min(d, 1)
+		}
+		if d <= max(2, len(key)/3) {
+			near = append(near, scored{c, d})
+		}
+	}
+	sort.SliceStable(near, func(i, j int) bool { return near[i].distance < near[j].distance })
+	var out []string
+	for _, s := range near {
+		if len(out) == limit {
+			break
+		}
+		out = append(out, s.name)
+	}
+	return out
+}
+
+// DidYouMean formats suggestions as " (did you mean a, b?)", or "" when there are none
+func DidYouMean(suggestions []string) string {
+	if len(suggestions) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean %s?)", strings.Join(suggestions, ", "))
+}
+
+// Distance returns the Levenshtein edit distance between two strings
+func Distance(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,62 @@
+package lookup
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIndex(t *testing.T) {
+	tests := map[string]string{
+		"Leather":        "leather-armor",
+		"Light Crossbow": "crossbow-light",
+		"Fireball":       "fireball",
+		"Plate":          "plate-armor",
+	}
+	for name, want := range tests {
+		if got := Index(name); got != want {
+			t.Errorf("Index(%q) = %q, want %q", name, got, want)
+		}
+	}
+	if !Matches("Leather", "leather armor") || !Matches("Studded Leather", "Studded Leather Armor") || Matches("Leather", "Hide") {
+		t.Errorf("Matches doesn't ignore the armor suffix")
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"Fireball", "Fire Bolt", "Firebolt Arrow", "Shield", "Shield of Faith", "Longsword"}
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"fierball", []string{"Fireball"}},
+		{"sheild", []string{"Shield"}},
+		{"long sword", []string{"Longsword"}},
+		{"banana", nil},
+	}
+	for _, tt := range tests {
+		if got := Suggest(tt.name, candidates, 3); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Suggest(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if got := DidYouMean([]string{"Fireball", "Fire Bolt"}); got != " (did you mean Fireball, Fire Bolt?)" {
+		t.Errorf("DidYouMean = %q", got)
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"shield", "shield", 0},
+		{"sheild", "shield", 2},
+		{"kitten", "sitting", 3},
+		{"", "abc", 3},
+	}
+	for _, tt := range tests {
+		if got := Distance(tt.a, tt.b); got != tt.want {
+			t.Errorf("Distance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package magicitem
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// MaxAttuned is the number of magic items a character can be attuned to at once
+const MaxAttuned = 3
+
+// MagicItem is a catalog entry for an SRD or homebrew magic item
+type MagicItem struct {
+	Name               string `json:"name"`
+	Type               string `json:"type"` // "weapon", "armor", "shield", "ring" or "wondrous"
+	Rarity             string `json:"rarity"`
+	RequiresAttunement bool   `json:"requires_attunement"`
+	ACBonus            int    `json:"ac_bonus,omitempty"`
+	SaveBonus          int    `json:"save_bonus,omitempty"`
+	AttackBonus        int    `json:"attack_bonus,omitempty"`
+	DamageBonus        int    `json:"damage_bonus,omitempty"`
+	Homebrew           bool   `json:"homebrew,omitempty"`
+}
+
+// LoadMagicItems loads the local magic item catalog from a JSON file
+func LoadMagicItems(filename string) ([]MagicItem, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var items []MagicItem
+	err = json.Unmarshal(data, &items)
+	return items, err
+}
@@ -0,0 +1,226 @@
+package magicitem
+
+import (
+	"fmt"
+	"modules/dndcharactersheet/internal/api"
+	characterModel "modules/dndcharactersheet/internal/character"
+	"modules/dndcharactersheet/internal/lookup"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// plusBonus matches the "+1" style enhancement bonus in SRD magic item names
+var plusBonus = regexp.MustCompile(`\+(\d)`)
+
+// FindMagicItem looks up a magic item in the local catalog, falling back to the SRD API.
+// Items only found in the API get their bonus from a "+N" in the name.
+func FindMagicItem(catalog []MagicItem, name string) (*MagicItem, error) {
+	names := make([]string, 0, len(catalog))
+	for _, it := range catalog {
+		if lookup.Matches(it.Name, name) {
+			return &it, nil
+		}
+		names = append(names, it.Name)
+	}
+
+	enriched, err := api.GetMagicItem(lookup.Index(name))
+	if err != nil || enriched == nil {
+		return nil, fmt.Errorf("magic item '%s' not found%s", name, lookup.DidYouMean(lookup.Suggest(name, names, 3)))
+	}
+	item := MagicItem{
+		Name:   enriched.Name,
+		Type:   strings.ToLower(enriched.EquipmentCategory.Name),
+		Rarity: strings.ToLower(enriched.Rarity.Name),
+	}
+	for _, line := range enriched.Desc {
+		if strings.Contains(strings.ToLower(line), "requires attunement") {
+			item.RequiresAttunement = true
+			break
+		}
+	}
+	if m := plusBonus.FindStringSubmatch(enriched.Name); m != nil {
+		bonus, _ := strconv.Atoi(m[1])
+		switch {
+		case strings.Contains(item.Type, "weapon"), strings.Contains(item.Type, "ammunition"):
+			item.Type = "weapon"
+			item.AttackBonus, item.DamageBonus = bonus, bonus
+		case strings.Contains(item.Type, "armor"), strings.Contains(item.Type, "shield"):
+			item.ACBonus = bonus
+		}
+	}
+	return &item, nil
+}
+
+// Acquire adds a magic item to the character, unequipped and unattuned. Items are told apart by
+// name, so the character can't hold two of the same item.
+func Acquire(char *characterModel.Character, item MagicItem) error {
+	if _, err := find(char, item.Name); err == nil {
+		return fmt.Errorf("%s already has %s", char.Name, strings.ToLower(item.Name))
+	}
+	char.MagicItems = append(char.MagicItems, characterModel.MagicItem{
+		Name:               strings.ToLower(item.Name),
+		Type:               item.Type,
+		Rarity:             item.Rarity,
+		RequiresAttunement: item.RequiresAttunement,
+		ACBonus:            item.ACBonus,
+		SaveBonus:          item.SaveBonus,
+		AttackBonus:        item.AttackBonus,
+		DamageBonus:        item.DamageBonus,
+	})
+	return nil
+}
+
+// find returns the character's magic item with the given name
+func find(char *characterModel.Character, name string) (*characterModel.MagicItem, error) {
+	for i := range char.MagicItems {
+		if strings.EqualFold(char.MagicItems[i].Name, strings.TrimSpace(name)) {
+			return &char.MagicItems[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%s doesn't have %s", char.Name, name)
+}
+
+// Remove drops a magic item from the character, ending any attunement
+func Remove(char *characterModel.Character, name string) error {
+	for i := range char.MagicItems {
+		if strings.EqualFold(char.MagicItems[i].Name, strings.TrimSpace(name)) {
+			char.MagicItems = append(char.MagicItems[:i], char.MagicItems[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("%s doesn't have %s", char.Name, name)
+}
+
+// Equip wears or wields a magic item. Magic weapons are bound to the weapon in the given hand,
+// magic armor and shields to the equipped armor or shield.
+func Equip(char *characterModel.Character, name string, slot string) error {
+	item, err := find(char, name)
+	if err != nil {
+		return err
+	}
+	boundTo := ""
+	switch item.Type {
+	case "weapon":
+		held := map[string]string{"main hand": char.MainHand, "off hand": char.OffHand}
+		weapon, ok := held[slot]
+		if !ok {
+			return fmt.Errorf("magic weapons need a -slot of main hand or off hand")
+		}
+		if weapon == "" {
+			return fmt.Errorf("no weapon equipped in the %s", slot)
+		}
+		boundTo = weapon
+	case "armor":
+		if char.Armor == "" {
+			return fmt.Errorf("no armor equipped")
+		}
+		slot, boundTo = "armor", char.Armor
+	case "shield":
+		if char.Shield == "" {
+			return fmt.Errorf("no shield equipped")
+		}
+		slot, boundTo = "shield", char.Shield
+	default:
+		slot = ""
+	}
+	item.Equipped = true
+	item.Slot = slot
+	item.BoundTo = boundTo
+	return nil
+}
+
+// Unequip stops wearing or wielding a magic item
+func Unequip(char *characterModel.Character, name string) error {
+	item, err := find(char, name)
+	if err != nil {
+		return err
+	}
+	item.Equipped = false
+	item.Slot = ""
+	item.BoundTo = ""
+	return nil
+}
+
+// AttunedCount returns how many magic items the character is attuned to
+func AttunedCount(char *characterModel.Character) int {
+	count := 0
+	for _, it := range char.MagicItems {
+		if it.Attuned {
+			count++
+		}
+	}
+	return count
+}
+
+// Attune attunes the character to a magic item, enforcing the limit of three attuned items
+func Attune(char *characterModel.Character, name string) error {
+	item, err := find(char, name)
+	if err != nil {
+		return err
+	}
+	if !item.RequiresAttunement {
+		return fmt.Errorf("%s doesn't require attunement", item.Name)
+	}
+	if item.Attuned {
+		return fmt.Errorf("already attuned to %s", item.Name)
+	}
+	if AttunedCount(char) >= MaxAttuned {
+		return fmt.Errorf("already attuned to %d items, end an attunement first", MaxAttuned)
+	}
+	item.Attuned = true
+	return nil
+}
+
+// Unattune ends the character's attunement to a magic item
+func Unattune(char *characterModel.Character, name string) error {
+	item, err := find(char, name)
+	if err != nil {
+		return err
+	}
+	if !item.Attuned {
+		return fmt.Errorf("not attuned to %s", item.Name)
+	}
+	item.Attuned = false
+	return nil
+}
+
+// IsActive reports whether a magic item's bonuses apply: it must be equipped, and attuned if it requires attunement
+func IsActive(item characterModel.MagicItem) bool {
+	return item.Equipped && (item.Attuned || !item.RequiresAttunement)
+}
+
+// FormatMagicItems returns a formatted string listing the character's magic items and attunement slots
+func FormatMagicItems(char *characterModel.Character) string {
+	if len(char.MagicItems) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Magic items (attuned %d/%d):\n", AttunedCount(char), MaxAttuned))
+	for _, it := range char.MagicItems {
+		var tags []string
+		if it.Equipped {
+			if it.BoundTo != "" {
+				tags = append(tags, fmt.Sprintf("equipped in %s with %s", it.Slot, it.BoundTo))
+			} else if it.Slot != "" {
+				tags = append(tags, "equipped in "+it.Slot)
+			} else {
+				tags = append(tags, "equipped")
+			}
+		}
+		if it.Attuned {
+			tags = append(tags, "attuned")
+		} else if it.RequiresAttunement {
+			tags = append(tags, "requires attunement")
+		}
+		if !IsActive(it) {
+			tags = append(tags, "inactive")
+		}
+		sb.WriteString(fmt.Sprintf("  %s (%s)", it.Name, it.Rarity))
+		if len(tags) > 0 {
+			sb.WriteString(" [" + strings.Join(tags, ", ") + "]")
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,23 @@
+package raceModel
+
+import (
+	"encoding/json"
+	"os"
+)
+
+type Race struct {
+	Name                string   `json:"name"`
+	Speed               int      `json:"speed"` // Base walking speed in feet
+	ArmorProficiencies  []string `json:"armor_proficiencies"`
+	WeaponProficiencies []string `json:"weapon_proficiencies"`
+}
+
+func LoadRaces(filename string) ([]Race, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var races []Race
+	err = json.Unmarshal(data, &races)
+	return races, err
+}
@@ -0,0 +1,157 @@
+package rest
+
+import (
+	"fmt"
+	"math/rand"
+	characterModel "modules/dndcharactersheet/internal/character"
+	"modules/dndcharactersheet/internal/condition"
+	"modules/dndcharactersheet/internal/spellcasting"
+	"strings"
+)
+
+// Recharge types for class resources
+const (
+	Short = "short"
+	Long  = "long"
+)
+
+// ShortRestResult describes what a short rest gave back
+type ShortRestResult struct {
+	Rolls        []int // Hit points from each hit die spent, Constitution modifier included
+	Healed       int
+	PactSlots    bool // Warlock Pact Magic slots were restored
+	Recharged    []string
+	DiceLeft     int
+	HitPointsNow int
+}
+
+// LongRestResult describes what a long rest gave back
+type LongRestResult struct {
+	Healed        int
+	DiceRecovered int
+	SlotsRestored bool
+	Recharged     []string
+	Exhaustion    int // Exhaustion level after the rest
+}
+
+// ShortRest spends hit dice to heal, each die rolled (or averaged) plus the Constitution modifier,
+// restores Pact Magic slots and recharges short-rest class resources. Hit dice can't be spent at
+// 0 hit points: a dying or stable character has to be healed first.
+func ShortRest(char *characterModel.Character, service *characterModel.CharacterService, dice int, average bool, rng *rand.Rand) (ShortRestResult, error) {
+	var result ShortRestResult
+	if dice < 0 {
+		return result, fmt.Errorf("hit dice to spend can't be negative")
+	}
+	if dice > char.HitDice {
+		return result, fmt.Errorf("only %d hit dice left to spend", char.HitDice)
+	}
+	if dice > 0 && char.HitDie == 0 {
+		return result, fmt.Errorf("no hit die known for class %s", char.Class)
+	}
+	if dice > 0 && char.CurrentHP == 0 && char.MaxHP > 0 {
+		return result, fmt.Errorf("%s is at 0 hit points and can't spend hit dice until healed", char.Name)
+	}
+
+	conMod := service.AbilityModifier(char.Con)
+	before := char.CurrentHP
+	for i := 0; i < dice; i++ {
+		roll := char.HitDie/2 + 1
+		if !average {
+			roll = rng.Intn(char.HitDie) + 1
+		}
+		hp := max(0, roll+conMod)
+		result.Rolls = append(result.Rolls, hp)
+		char.CurrentHP = min(condition.MaxHP(char), char.CurrentHP+hp)
+	}
+	char.HitDice -= dice
+	result.Healed = char.CurrentHP - before
+
+	cs := spellcasting.ForCharacter(char)
+	if cs.CasterType == spellcasting.CasterPact {
+		spellcasting.RestoreSlots(&cs)
+		char.Spellcasting = cs
+		result.PactSlots = true
+	}
+	result.Recharged = recharge(char, Short)
+	result.DiceLeft = char.HitDice
+	result.HitPointsNow = char.CurrentHP
+	return result, nil
+}
+
+// LongRest restores all hit points and spell slots, regains up to half the character's total
+// hit dice (at least one), recharges every class resource and removes one level of exhaustion.
+// The SRD only grants these to a character with at least 1 hit point at the start of the rest.
+func LongRest(char *characterModel.Character) (LongRestResult, error) {
+	var result LongRestResult
+	if char.CurrentHP == 0 && char.MaxHP > 0 {
+		return result, fmt.Errorf("%s needs at least 1 hit point to benefit from a long rest", char.Name)
+	}
+	if char.Exhaustion > 0 {
+		char.Exhaustion--
+	}
+	result.Exhaustion = char.Exhaustion
+	before := char.CurrentHP
+	char.CurrentHP = condition.MaxHP(char)
+	result.Healed = char.CurrentHP - before
+
+	regained := min(max(1, char.Level/2), char.Level-char.HitDice)
+	if regained > 0 {
+		char.HitDice += regained
+		result.DiceRecovered = regained
+	}
+
+	cs := spellcasting.ForCharacter(char)
+	if spellcasting.CanCastSpells(cs.CasterType) {
+		spellcasting.RestoreSlots(&cs)
+		char.Spellcasting = cs
+		result.SlotsRestored = true
+	}
+	result.Recharged = recharge(char, Long)
+	return result, nil
+}
+
+// recharge refills class resources; a long rest refills short-rest resources too
+func recharge(char *characterModel.Character, restType string) []string {
+	var recharged []string
+	for i, r := range char.Resources {
+		if restType == Short && r.Recharge != Short {
+			continue
+		}
+		if r.Remaining < r.Max {
+			recharged = append(recharged, r.Name)
+		}
+		char.Resources[i].Remaining = r.Max
+	}
+	return recharged
+}
+
+// UseResource spends uses of a class resource
+func UseResource(char *characterModel.Character, name string, amount int) (*characterModel.Resource, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("amount must be positive")
+	}
+	for i, r := range char.Resources {
+		if !strings.EqualFold(r.Name, strings.TrimSpace(name)) {
+			continue
+		}
+		if r.Remaining < amount {
+			return nil, fmt.Errorf("only %d %s left", r.Remaining, r.Name)
+		}
+		char.Resources[i].Remaining -= amount
+		return &char.Resources[i], nil
+	}
+	return nil, fmt.Errorf("%s has no resource %q", char.Name, name)
+}
+
+// FormatResources returns the class resources with their uses left, e.g. "  Rage: 1/2 (long rest)"
+func FormatResources(char *characterModel.Character) string {
+	if len(char.Resources) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("Resources:\n")
+	for _, r := range char.Resources {
+		sb.WriteString(fmt.Sprintf("  %s: %d/%d (%s rest)\n", r.Name, r.Remaining, r.Max, r.Recharge))
+	}
+	return sb.String()
+}
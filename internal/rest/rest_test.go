@@ -0,0 +1,58 @@
+package rest
+
+import (
+	characterModel "modules/dndcharactersheet/internal/character"
+	"testing"
+)
+
+// fighter returns a level 4 fighter with 14 CON who has spent two hit dice
+func fighter(hp int) *characterModel.Character {
+	return &characterModel.Character{Name: "Tordek", Class: "fighter", Level: 4, Con: 14,
+		MaxHP: 36, CurrentHP: hp, HitDie: 10, HitDice: 2}
+}
+
+func TestLongRest(t *testing.T) {
+	char := fighter(5)
+	char.Exhaustion = 2
+	result, err := LongRest(char)
+	if err != nil {
+		t.Fatalf("LongRest failed: %v", err)
+	}
+	if char.CurrentHP != 36 || result.Healed != 31 {
+		t.Errorf("LongRest left %d hit points, healed %d; want 36, healed 31", char.CurrentHP, result.Healed)
+	}
+	if char.HitDice != 4 || result.DiceRecovered != 2 {
+		t.Errorf("LongRest left %d hit dice, recovered %d; want 4, recovered 2", char.HitDice, result.DiceRecovered)
+	}
+	if char.Exhaustion != 1 {
+		t.Errorf("LongRest left exhaustion %d, want 1", char.Exhaustion)
+	}
+}
+
+func TestRestAtZeroHitPoints(t *testing.T) {
+	char := fighter(0)
+	char.Stable = true
+	char.Conditions = []string{"unconscious"}
+	char.Exhaustion = 1
+	if _, err := LongRest(char); err == nil {
+		t.Errorf("LongRest at 0 hit points succeeded")
+	}
+	if _, err := ShortRest(char, characterModel.NewCharacterService(), 1, true, nil); err == nil {
+		t.Errorf("ShortRest spending hit dice at 0 hit points succeeded")
+	}
+	if char.CurrentHP != 0 || char.HitDice != 2 || char.Exhaustion != 1 || !char.Stable || len(char.Conditions) != 1 {
+		t.Errorf("refused rests changed the character: %+v", char)
+	}
+}
+
+func TestShortRestAverage(t *testing.T) {
+	char := fighter(10)
+	result, err := ShortRest(char, characterModel.NewCharacterService(), 2, true, nil)
+	if err != nil {
+		t.Fatalf("ShortRest failed: %v", err)
+	}
+	// each d10 averages 6, plus 2 for CON 14
+	if result.Healed != 16 || char.CurrentHP != 26 || char.HitDice != 0 {
+		t.Errorf("ShortRest healed %d to %d hit points with %d hit dice left; want 16, 26 and 0", result.Healed, char.CurrentHP, char.HitDice)
+	}
+}
@@ -18,13 +18,21 @@ func AssignSpellcasting(class string, level int) CharacterSpellcasting {
 		slots = HalfCasterSlots[level]
 	case CasterPact:
 		slots = PactCasterSlots[level]
+	case CasterKnown:
+		slots = GetDefaultSpellSlots(class, level)
 	default:
 		slots = map[int]int{}
 	}
-	return CharacterSpellcasting{
+	cs := CharacterSpellcasting{
 		CasterType:     casterType,
 		KnownSpells:    []string{},
 		PreparedSpells: []string{},
+		Cantrips:       []string{},
 		SpellSlots:     slots,
 	}
+	if UsesSpellbook(class) {
+		cs.Spellbook = []string{}
+	}
+	RestoreSlots(&cs)
+	return cs
 }
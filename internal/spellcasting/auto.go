@@ -0,0 +1,105 @@
+package spellcasting
+
+import (
+	"math/rand"
+	"strings"
+)
+
+// AutoOptions steers the random picks of AutoAssign
+type AutoOptions struct {
+	Prefer []string // Schools, or the "ritual" tag, to pick more often
+	Weight int      // How many times likelier a preferred spell is picked than any other
+}
+
+// AutoResult lists the spells AutoAssign added
+type AutoResult struct {
+	Cantrips  []string
+	Spellbook []string
+	Known     []string
+	Prepared  []string
+}
+
+// AutoAssign fills the character's cantrips, spellbook, known and prepared spells up to the class
+// limits with random spells from the class list that fit the spell slots. Spells already chosen
+// are kept. The same rng seed and spell list give the same picks.
+func AutoAssign(cs *CharacterSpellcasting, classSpells []Spell, class string, level int, preparedLimit int, rng *rand.Rand, opts AutoOptions) AutoResult {
+	var result AutoResult
+	if !CanCastSpells(cs.CasterType) {
+		return result
+	}
+	castable := func(s Spell) bool { return s.Level > 0 && s.Level <= highestSlot(cs) }
+
+	cantrips := pool(classSpells, func(s Spell) bool { return s.Level == 0 && !HasSpell(cs, s.Name) })
+	result.Cantrips = fill(cantrips, GetCantripsKnown(class, level)-len(cs.Cantrips), rng, opts, func(s Spell) bool {
+		return learnSpell(cs, s, class, level) == nil
+	})
+
+	if cs.Spellbook != nil {
+		book := pool(classSpells, func(s Spell) bool { return castable(s) && !InSpellbook(cs, s.Name) })
+		result.Spellbook = fill(book, SpellbookAllowance(level)-LeveledSpellbookCount(cs), rng, opts, func(s Spell) bool {
+			return AddToSpellbook(cs, s, level) == nil
+		})
+	}
+
+	switch cs.CasterType {
+	case CasterKnown, CasterPact:
+		known := pool(classSpells, func(s Spell) bool { return castable(s) && !HasSpell(cs, s.Name) })
+		result.Known = fill(known, GetSpellsKnown(class, level)-len(cs.KnownSpells), rng, opts, func(s Spell) bool {
+			return learnSpell(cs, s, class, level) == nil
+		})
+	case CasterFull, CasterHalf:
+		prepared := pool(classSpells, func(s Spell) bool {
+			return castable(s) && !HasSpell(cs, s.Name) && (cs.Spellbook == nil || InSpellbook(cs, s.Name))
+		})
+		result.Prepared = fill(prepared, preparedLimit-PreparedCount(cs, classSpells), rng, opts, func(s Spell) bool {
+			return prepareSpell(cs, s, classSpells, preparedLimit) == nil
+		})
+	}
+	return result
+}
+
+// pool returns the spells that pass the filter
+func pool(spells []Spell, keep func(Spell) bool) []Spell {
+	var out []Spell
+	for _, s := range spells {
+		if keep(s) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// fill picks up to need spells from the pool at random, weighted by the options, and returns
+// the names of those add accepted
+func fill(candidates []Spell, need int, rng *rand.Rand, opts AutoOptions, add func(Spell) bool) []string {
+	var added []string
+	for len(added) < need && len(candidates) > 0 {
+		total := 0
+		for _, s := range candidates {
+			total += autoWeight(s, opts)
+		}
+		pick, roll := 0, rng.Intn(total)
+		for i, s := range candidates {
+			if roll -= autoWeight(s, opts); roll < 0 {
+				pick = i
+				break
+			}
+		}
+		spell := candidates[pick]
+		candidates = append(candidates[:pick], candidates[pick+1:]...)
+		if add(spell) {
+			added = append(added, spell.Name)
+		}
+	}
+	return added
+}
+
+// autoWeight returns how likely a spell is picked relative to others
+func autoWeight(s Spell, opts AutoOptions) int {
+	for _, p := range opts.Prefer {
+		if strings.EqualFold(p, s.School) || (strings.EqualFold(p, "ritual") && s.Ritual) {
+			return max(1, opts.Weight)
+		}
+	}
+	return 1
+}
@@ -0,0 +1,37 @@
+package spellcasting
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestAutoAssign(t *testing.T) {
+	var spells []Spell
+	for _, name := range []string{"Acid Splash", "Fire Bolt", "Light", "Mage Hand", "Prestidigitation", "Ray of Frost"} {
+		spells = append(spells, Spell{Name: name, Level: 0})
+	}
+	for _, name := range []string{"Burning Hands", "Magic Missile", "Shield", "Sleep"} {
+		spells = append(spells, Spell{Name: name, Level: 1})
+	}
+	spells = append(spells, Spell{Name: "Scorching Ray", Level: 2})
+
+	sorcerer := &CharacterSpellcasting{CasterType: CasterKnown, Cantrips: []string{"Light"}, SpellSlots: map[int]int{1: 2}}
+	result := AutoAssign(sorcerer, spells, "sorcerer", 1, 0, rand.New(rand.NewSource(1)), AutoOptions{})
+	if len(result.Cantrips) != 3 || len(sorcerer.Cantrips) != 4 {
+		t.Errorf("sorcerer cantrips: added %v, knows %v; want 4 in all", result.Cantrips, sorcerer.Cantrips)
+	}
+	if len(result.Known) != 2 || len(sorcerer.KnownSpells) != 2 {
+		t.Errorf("sorcerer spells: added %v, knows %v; want 2", result.Known, sorcerer.KnownSpells)
+	}
+	for _, name := range sorcerer.KnownSpells {
+		if name == "Scorching Ray" {
+			t.Errorf("sorcerer learned %s without a level 2 slot", name)
+		}
+	}
+
+	cleric := &CharacterSpellcasting{CasterType: CasterFull, SpellSlots: map[int]int{1: 2}}
+	result = AutoAssign(cleric, spells, "cleric", 1, 3, rand.New(rand.NewSource(1)), AutoOptions{})
+	if len(result.Cantrips) != 3 || len(result.Prepared) != 3 || len(cleric.PreparedSpells) != 3 {
+		t.Errorf("cleric: added cantrips %v and prepared %v, prepares %v; want 3 of each", result.Cantrips, result.Prepared, cleric.PreparedSpells)
+	}
+}
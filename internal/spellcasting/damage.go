@@ -0,0 +1,197 @@
+package spellcasting
+
+import (
+	"fmt"
+	"modules/dndcharactersheet/internal/api"
+	"modules/dndcharactersheet/internal/lookup"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// SpellDamage holds a spell's damage dice as the API reports them
+type SpellDamage struct {
+	Type             string
+	AtSlotLevel      map[int]string // Leveled spells: damage dice by the slot level cast with
+	AtCharacterLevel map[int]string // Cantrips: damage dice from each character level on
+	Attack           string         // "melee" or "ranged" when the spell makes a spell attack
+	Save             string         // Saving throw ability, e.g. "dex", when the target saves instead
+}
+
+// CantripTiers are the character levels at which cantrip damage grows by one die
+var CantripTiers = []int{1, 5, 11, 17}
+
+// cantripDamage builds the damage of a cantrip that gains a die at levels 5, 11 and 17
+func cantripDamage(damageType string, die string, attack string, save string) SpellDamage {
+	byLevel := make(map[int]string, len(CantripTiers))
+	for i, lvl := range CantripTiers {
+		byLevel[lvl] = addDice(die, die, i)
+	}
+	return SpellDamage{Type: damageType, AtCharacterLevel: byLevel, Attack: attack, Save: save}
+}
+
+// upcastDamage builds the damage of a spell of the given level that deals the base dice, plus the
+// step dice for each slot level above it; an empty step means upcasting doesn't add damage
+func upcastDamage(damageType string, level int, base string, step string, attack string, save string) SpellDamage {
+	bySlot := make(map[int]string, 10-level)
+	for slot := level; slot <= 9; slot++ {
+		bySlot[slot] = base
+		if step != "" {
+			bySlot[slot] = addDice(base, step, slot-level)
+		}
+	}
+	return SpellDamage{Type: damageType, AtSlotLevel: bySlot, Attack: attack, Save: save}
+}
+
+// addDice adds times the step dice to a damage expression like "8d6" or "10d6+40"; the step
+// must use the same die
+func addDice(base string, step string, times int) string {
+	dice, bonus, _ := strings.Cut(base, "+")
+	count, sides, _ := strings.Cut(dice, "d")
+	stepCount, _, _ := strings.Cut(step, "d")
+	n, _ := strconv.Atoi(count)
+	s, _ := strconv.Atoi(stepCount)
+	out := fmt.Sprintf("%dd%s", n+s*times, sides)
+	if bonus != "" {
+		out += "+" + bonus
+	}
+	return out
+}
+
+// srdSpellDamage is the offline fallback for the damage of SRD spells, keyed by API index
+var srdSpellDamage = map[string]SpellDamage{
+	"acid-splash":     cantripDamage("acid", "1d6", "", "dex"),
+	"chill-touch":     cantripDamage("necrotic", "1d8", "ranged", ""),
+	"eldritch-blast":  cantripDamage("force", "1d10", "ranged", ""),
+	"fire-bolt":       cantripDamage("fire", "1d10", "ranged", ""),
+	"poison-spray":    cantripDamage("poison", "1d12", "", "con"),
+	"produce-flame":   cantripDamage("fire", "1d8", "ranged", ""),
+	"ray-of-frost":    cantripDamage("cold", "1d8", "ranged", ""),
+	"sacred-flame":    cantripDamage("radiant", "1d8", "", "dex"),
+	"shocking-grasp":  cantripDamage("lightning", "1d8", "melee", ""),
+	"vicious-mockery": cantripDamage("psychic", "1d4", "", "wis"),
+
+	"burning-hands":  upcastDamage("fire", 1, "3d6", "1d6", "", "dex"),
+	"guiding-bolt":   upcastDamage("radiant", 1, "4d6", "1d6", "ranged", ""),
+	"hellish-rebuke": upcastDamage("fire", 1, "2d10", "1d10", "", "dex"),
+	"inflict-wounds": upcastDamage("necrotic", 1, "3d10", "1d10", "melee", ""),
+	"thunderwave":    upcastDamage("thunder", 1, "2d8", "1d8", "", "con"),
+	"magic-missile": {Type: "force", AtSlotLevel: map[int]string{
+		1: "3d4+3", 2: "4d4+4", 3: "5d4+5", 4: "6d4+6", 5: "7d4+7", 6: "8d4+8", 7: "9d4+9", 8: "10d4+10", 9: "11d4+11",
+	}},
+	"branding-smite": upcastDamage("radiant", 2, "2d6", "1d6", "melee", ""),
+	"acid-arrow":     upcastDamage("acid", 2, "4d4", "1d4", "ranged", ""),
+	"flaming-sphere": upcastDamage("fire", 2, "2d6", "1d6", "", "dex"),
+	"heat-metal":     upcastDamage("fire", 2, "2d8", "1d8", "", ""),
+	"moonbeam":       upcastDamage("radiant", 2, "2d10", "1d10", "", "con"),
+	"scorching-ray":  upcastDamage("fire", 2, "2d6", "", "ranged", ""),
+	"shatter":        upcastDamage("thunder", 2, "3d8", "1d8", "", "con"),
+	"spiritual-weapon": {Type: "force", Attack: "melee", AtSlotLevel: map[int]string{
+		2: "1d8", 3: "1d8", 4: "2d8", 5: "2d8", 6: "3d8", 7: "3d8", 8: "4d8", 9: "4d8",
+	}},
+	"call-lightning":         upcastDamage("lightning", 3, "3d10", "1d10", "", "dex"),
+	"fireball":               upcastDamage("fire", 3, "8d6", "1d6", "", "dex"),
+	"lightning-bolt":         upcastDamage("lightning", 3, "8d6", "1d6", "", "dex"),
+	"vampiric-touch":         upcastDamage("necrotic", 3, "3d6", "1d6", "melee", ""),
+	"blight":                 upcastDamage("necrotic", 4, "8d8", "1d8", "", "con"),
+	"ice-storm":              upcastDamage("bludgeoning", 4, "2d8", "1d8", "", "dex"),
+	"cloudkill":              upcastDamage("poison", 5, "5d8", "1d8", "", "con"),
+	"cone-of-cold":           upcastDamage("cold", 5, "8d8", "1d8", "", "con"),
+	"flame-strike":           upcastDamage("fire", 5, "4d6", "1d6", "", "dex"),
+	"insect-plague":          upcastDamage("piercing", 5, "4d10", "1d10", "", "con"),
+	"wall-of-fire":           upcastDamage("fire", 4, "5d8", "1d8", "", "dex"),
+	"blade-barrier":          upcastDamage("slashing", 6, "6d10", "", "", "dex"),
+	"chain-lightning":        upcastDamage("lightning", 6, "10d8", "", "", "dex"),
+	"circle-of-death":        upcastDamage("necrotic", 6, "8d6", "2d6", "", "con"),
+	"disintegrate":           upcastDamage("force", 6, "10d6+40", "3d6", "", "dex"),
+	"freezing-sphere":        upcastDamage("cold", 6, "10d6", "1d6", "", "con"),
+	"harm":                   upcastDamage("necrotic", 6, "14d6", "", "", "con"),
+	"sunbeam":                upcastDamage("radiant", 6, "6d8", "", "", "con"),
+	"delayed-blast-fireball": upcastDamage("fire", 7, "12d6", "1d6", "", "dex"),
+	"finger-of-death":        upcastDamage("necrotic", 7, "7d8+30", "", "", "con"),
+	"fire-storm":             upcastDamage("fire", 7, "7d10", "", "", "dex"),
+	"prismatic-spray":        upcastDamage("fire", 7, "10d6", "", "", "dex"),
+	"incendiary-cloud":       upcastDamage("fire", 8, "10d8", "", "", "dex"),
+	"sunburst":               upcastDamage("radiant", 8, "12d6", "", "", "con"),
+	"meteor-swarm":           upcastDamage("fire", 9, "20d6", "", "", "dex"),
+}
+
+// fetchSpell loads a spell's details from the API
+var fetchSpell = api.GetSpell
+
+var (
+	enrichedMu     sync.Mutex
+	enrichedDamage = map[string]enrichedSpell{} // API lookups of spells missing from the SRD table, by index
+)
+
+// enrichedSpell is the damage the API reported for a spell, found is false when it deals none
+type enrichedSpell struct {
+	damage SpellDamage
+	found  bool
+}
+
+// LookupSpellDamage returns the spell's damage from the SRD table, asking the API only for spells
+// the table doesn't have. Each API answer, including a failed request, is kept for the rest of the
+// process so a damage table asks at most once per spell. It reports false for spells that deal no damage.
+func LookupSpellDamage(name string) (SpellDamage, bool) {
+	idx := lookup.Index(name)
+	if damage, found := srdSpellDamage[idx]; found {
+		return damage, true
+	}
+
+	enrichedMu.Lock()
+	defer enrichedMu.Unlock()
+	if cached, ok := enrichedDamage[idx]; ok {
+		return cached.damage, cached.found
+	}
+	var cached enrichedSpell
+	spell, err := fetchSpell(idx)
+	if err == nil && spell != nil && (len(spell.Damage.DamageAtSlotLevel) > 0 || len(spell.Damage.DamageAtCharacterLevel) > 0) {
+		cached.damage = SpellDamage{
+			Type:             strings.ToLower(spell.Damage.DamageType.Name),
+			AtSlotLevel:      levelMap(spell.Damage.DamageAtSlotLevel),
+			AtCharacterLevel: levelMap(spell.Damage.DamageAtCharacterLevel),
+			Attack:           strings.ToLower(spell.AttackType),
+			Save:             strings.ToLower(spell.DC.DCType.Index),
+		}
+		cached.found = true
+	}
+	enrichedDamage[idx] = cached
+	return cached.damage, cached.found
+}
+
+// levelMap converts the API's level keys to ints and drops spaces from the dice, "1d8 + 3" to "1d8+3"
+func levelMap(byLevel map[string]string) map[int]string {
+	if len(byLevel) == 0 {
+		return nil
+	}
+	out := make(map[int]string, len(byLevel))
+	for k, v := range byLevel {
+		if lvl, err := strconv.Atoi(k); err == nil {
+			out[lvl] = strings.ReplaceAll(v, " ", "")
+		}
+	}
+	return out
+}
+
+// DiceAt returns the damage dice for a cast: for cantrips those of the highest tier the character
+// level reaches, for leveled spells those of the slot level
+func (d SpellDamage) DiceAt(characterLevel int, slot int) (string, bool) {
+	if len(d.AtCharacterLevel) > 0 {
+		levels := make([]int, 0, len(d.AtCharacterLevel))
+		for lvl := range d.AtCharacterLevel {
+			levels = append(levels, lvl)
+		}
+		sort.Ints(levels)
+		dice := ""
+		for _, lvl := range levels {
+			if lvl <= characterLevel {
+				dice = d.AtCharacterLevel[lvl]
+			}
+		}
+		return dice, dice != ""
+	}
+	dice, ok := d.AtSlotLevel[slot]
+	return dice, ok
+}
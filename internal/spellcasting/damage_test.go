@@ -0,0 +1,74 @@
+package spellcasting
+
+import (
+	"encoding/json"
+	"fmt"
+	"modules/dndcharactersheet/internal/api"
+	"testing"
+)
+
+// countFetches replaces the API lookup for the test with one serving the spells from JSON,
+// returning the number of lookups made so far per index
+func countFetches(t *testing.T, spells map[string]string) map[string]int {
+	calls := map[string]int{}
+	previous := fetchSpell
+	fetchSpell = func(index string) (*api.SpellEnriched, error) {
+		calls[index]++
+		data, ok := spells[index]
+		if !ok {
+			return nil, fmt.Errorf("API returned status: 404 Not Found")
+		}
+		var spell api.SpellEnriched
+		if err := json.Unmarshal([]byte(data), &spell); err != nil {
+			t.Fatal(err)
+		}
+		return &spell, nil
+	}
+	t.Cleanup(func() {
+		fetchSpell = previous
+		enrichedDamage = map[string]enrichedSpell{}
+	})
+	return calls
+}
+
+func TestLookupSpellDamageOffline(t *testing.T) {
+	calls := countFetches(t, nil)
+	damage, found := LookupSpellDamage("Fireball")
+	if !found || damage.Type != "fire" || damage.Save != "dex" {
+		t.Fatalf("LookupSpellDamage(Fireball) = %+v, %v", damage, found)
+	}
+	if dice, _ := damage.DiceAt(5, 5); dice != "10d6" {
+		t.Errorf("Fireball at level 5 = %s, want 10d6", dice)
+	}
+	if dice, _ := LookupSpellDamage("Fire Bolt"); dice.AtCharacterLevel[11] != "3d10" {
+		t.Errorf("Fire Bolt at level 11 = %s, want 3d10", dice.AtCharacterLevel[11])
+	}
+	if len(calls) > 0 {
+		t.Errorf("spells in the SRD table were looked up in the API: %v", calls)
+	}
+}
+
+func TestLookupSpellDamageEnrichesOnce(t *testing.T) {
+	calls := countFetches(t, map[string]string{
+		"storm-sphere": `{"index": "storm-sphere", "level": 4, "damage": {"damage_type": {"name": "Bludgeoning"},
+			"damage_at_slot_level": {"4": "2d6", "5": "3d6"}}, "dc": {"dc_type": {"index": "str"}}}`,
+		"bless": `{"index": "bless", "level": 1}`,
+	})
+	for range 3 {
+		damage, found := LookupSpellDamage("Storm Sphere")
+		if !found || damage.Type != "bludgeoning" || damage.Save != "str" || damage.AtSlotLevel[5] != "3d6" {
+			t.Fatalf("LookupSpellDamage(Storm Sphere) = %+v, %v", damage, found)
+		}
+		if _, found := LookupSpellDamage("Bless"); found {
+			t.Errorf("LookupSpellDamage(Bless) found damage")
+		}
+		if _, found := LookupSpellDamage("Unknown Spell"); found {
+			t.Errorf("LookupSpellDamage(Unknown Spell) found damage")
+		}
+	}
+	for _, idx := range []string{"storm-sphere", "bless", "unknown-spell"} {
+		if calls[idx] != 1 {
+			t.Errorf("%s was looked up %d times, want once", idx, calls[idx])
+		}
+	}
+}
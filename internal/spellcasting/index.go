@@ -0,0 +1,110 @@
+package spellcasting
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SpellIndex looks spells up by class, level and school without scanning the whole list
+type SpellIndex struct {
+	spells   []Spell
+	byClass  map[string][]int
+	byLevel  map[int][]int
+	bySchool map[string][]int
+}
+
+// SpellQuery filters the spells returned by SpellIndex.Query. Empty fields match every spell.
+type SpellQuery struct {
+	Class  string
+	Level  int // -1 for any level, 0 for cantrips
+	School string
+	Ritual bool   // Only ritual spells
+	Text   string // Part of the spell name
+}
+
+// NewSpellIndex indexes a spell list
+func NewSpellIndex(spells []Spell) *SpellIndex {
+	idx := &SpellIndex{
+		spells:   spells,
+		byClass:  map[string][]int{},
+		byLevel:  map[int][]int{},
+		bySchool: map[string][]int{},
+	}
+	for i, s := range spells {
+		for _, class := range s.Class {
+			key := strings.ToLower(class)
+			idx.byClass[key] = append(idx.byClass[key], i)
+		}
+		idx.byLevel[s.Level] = append(idx.byLevel[s.Level], i)
+		school := strings.ToLower(s.School)
+		idx.bySchool[school] = append(idx.bySchool[school], i)
+	}
+	return idx
+}
+
+// Classes returns the classes with a spell list, sorted
+func (idx *SpellIndex) Classes() []string {
+	classes := make([]string, 0, len(idx.byClass))
+	for class := range idx.byClass {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	return classes
+}
+
+// Query returns the spells matching every filter in the query, sorted by level and name
+func (idx *SpellIndex) Query(q SpellQuery) []Spell {
+	// start from the narrowest indexed filter
+	candidates := make([]int, len(idx.spells))
+	for i := range candidates {
+		candidates[i] = i
+	}
+	if q.Class != "" {
+		candidates = idx.byClass[strings.ToLower(strings.TrimSpace(q.Class))]
+	}
+	if q.School != "" {
+		if bySchool := idx.bySchool[strings.ToLower(strings.TrimSpace(q.School))]; len(bySchool) < len(candidates) {
+			candidates = bySchool
+		}
+	}
+	if q.Level >= 0 {
+		if byLevel := idx.byLevel[q.Level]; len(byLevel) < len(candidates) {
+			candidates = byLevel
+		}
+	}
+
+	text := strings.ToLower(strings.TrimSpace(q.Text))
+	var out []Spell
+	for _, i := range candidates {
+		s := idx.spells[i]
+		switch {
+		case q.Class != "" && !s.HasClass(q.Class):
+		case q.School != "" && !strings.EqualFold(s.School, strings.TrimSpace(q.School)):
+		case q.Level >= 0 && s.Level != q.Level:
+		case q.Ritual && !s.Ritual:
+		case text != "" && !strings.Contains(strings.ToLower(s.Name), text):
+		default:
+			out = append(out, s)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Level != out[j].Level {
+			return out[i].Level < out[j].Level
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// FormatSpell returns a one-line description, e.g. "Fireball (level 3 evocation; Sorcerer, Wizard)"
+func FormatSpell(s Spell) string {
+	kind := fmt.Sprintf("level %d %s", s.Level, s.School)
+	if s.Level == 0 {
+		kind = s.School + " cantrip"
+	}
+	if s.Ritual {
+		kind += ", ritual"
+	}
+	return fmt.Sprintf("%s (%s; %s)", s.Name, kind, strings.Join(s.Class, ", "))
+}
@@ -14,9 +14,36 @@ const (
 )
 
 type Spell struct {
-	Name  string
-	Level int
-	Class string
+	Name          string
+	Level         int
+	Class         []string // Classes with the spell on their spell list
+	School        string
+	Ritual        bool
+	Concentration bool
+	CastingTime   string // e.g. "1 action", known only once enriched from the API
+}
+
+// HasClass reports whether the spell is on the class's spell list
+func (s Spell) HasClass(class string) bool {
+	for _, c := range s.Class {
+		if strings.EqualFold(c, strings.TrimSpace(class)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Schools lists the schools of magic
+var Schools = []string{"abjuration", "conjuration", "divination", "enchantment", "evocation", "illusion", "necromancy", "transmutation"}
+
+// IsSchool reports whether the name is a school of magic
+func IsSchool(name string) bool {
+	for _, school := range Schools {
+		if strings.EqualFold(school, strings.TrimSpace(name)) {
+			return true
+		}
+	}
+	return false
 }
 
 // CharacterSpellcasting holds spellcasting data for a character
@@ -26,6 +53,12 @@ type CharacterSpellcasting struct {
 	KnownSpells    []string
 	PreparedSpells []string
 	SpellSlots     map[int]int // level -> slots
+	CurrentSlots   map[int]int // level -> slots left until the next rest
+	Cantrips       []string    // Known cantrips, counted apart from leveled spells
+	ReplacedAt     int         // Class level at which a known spell was last swapped for another
+	Spellbook      []string    // Wizard spells written in the spellbook; nil for classes without one
+	CopiedSpells   []string    // Spellbook spells copied from scrolls or other books, beyond those gained by level
+	AlwaysPrepared []string    // Domain or oath spells that are prepared without counting against the limit
 }
 
 // CasterTypeByClass maps class names to their caster type
@@ -36,7 +69,7 @@ var CasterTypeByClass = map[string]CasterType{
 	"bard":     CasterKnown,
 	"sorcerer": CasterKnown,
 	"paladin":  CasterHalf,
-	"ranger":   CasterHalf,
+	"ranger":   CasterKnown,
 	"warlock":  CasterPact,
 }
 
@@ -50,6 +83,28 @@ var CantripsKnownByClassAndLevel = map[string][]int{
 	"wizard":   {0, 3, 3, 3, 4, 4, 4, 4, 4, 4, 5, 5, 5, 5, 5, 5, 5, 5, 5, 5, 5},
 }
 
+// SpellsKnownByClassAndLevel maps known-spell classes to a slice where index is level and value is
+// leveled spells known
+var SpellsKnownByClassAndLevel = map[string][]int{
+	"bard":     {0, 4, 5, 6, 7, 8, 9, 10, 11, 12, 14, 15, 15, 16, 18, 19, 19, 20, 22, 22, 22},
+	"ranger":   {0, 0, 2, 3, 3, 4, 4, 5, 5, 6, 6, 7, 7, 8, 8, 9, 9, 10, 10, 11, 11},
+	"sorcerer": {0, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 12, 13, 13, 14, 14, 15, 15, 15, 15},
+	"warlock":  {0, 2, 3, 4, 5, 6, 7, 8, 9, 10, 10, 11, 11, 12, 12, 13, 13, 14, 14, 15, 15},
+}
+
+// GetSpellsKnown returns the number of leveled spells known for a class and level
+func GetSpellsKnown(class string, level int) int {
+	if arr, ok := SpellsKnownByClassAndLevel[strings.ToLower(class)]; ok {
+		if level >= 1 && level < len(arr) {
+			return arr[level]
+		}
+		if level >= len(arr) {
+			return arr[len(arr)-1]
+		}
+	}
+	return 0
+}
+
 // GetCantripsKnown returns the number of cantrips known for a class and level
 func GetCantripsKnown(class string, level int) int {
 	class = strings.ToLower(class)
@@ -3,33 +3,18 @@ package spellcasting
 import (
 	"encoding/csv"
 	"fmt"
+	"modules/dndcharactersheet/internal/lookup"
 	"os"
 	"strconv"
 	"strings"
 )
 
-// FormatCantrips returns a formatted string for cantrips (level 0 spells) from SpellSlots or KnownSpells
-func FormatCantrips(cs *CharacterSpellcasting) string {
-	if cs == nil {
+// FormatCantrips returns the character's known cantrips with the number the class can know
+func FormatCantrips(cs *CharacterSpellcasting, class string, level int) string {
+	if cs == nil || len(cs.Cantrips) == 0 {
 		return ""
 	}
-	// If cantrips are tracked in SpellSlots (level 0)
-	if slots, exists := cs.SpellSlots[0]; exists {
-		return fmt.Sprintf("Spell slots:\n  Level 0: %d\n", slots)
-	}
-	// If cantrips are tracked in KnownSpells
-	if len(cs.KnownSpells) > 0 {
-		var cantrips []string
-		for _, spellName := range cs.KnownSpells {
-			if strings.Contains(strings.ToLower(spellName), "cantrip") || strings.Contains(strings.ToLower(spellName), "level 0") {
-				cantrips = append(cantrips, spellName)
-			}
-		}
-		if len(cantrips) > 0 {
-			return fmt.Sprintf("Cantrips: %s\n", strings.Join(cantrips, ", "))
-		}
-	}
-	return ""
+	return fmt.Sprintf("Cantrips (%d/%d): %s\n", len(cs.Cantrips), GetCantripsKnown(class, level), strings.Join(cs.Cantrips, ", "))
 }
 
 // Returns a formatted string for a character's spell slots
@@ -48,7 +33,7 @@ func FormatSpellSlots(cs *CharacterSpellcasting, class string, level int) string
 	if cs.SpellSlots != nil {
 		for lvl := 1; lvl <= 9; lvl++ {
 			if slots, exists := cs.SpellSlots[lvl]; exists {
-				sb.WriteString(fmt.Sprintf("  Level %d: %d\n", lvl, slots))
+				sb.WriteString(fmt.Sprintf("  Level %d: %d/%d\n", lvl, RemainingSlots(cs, lvl), slots))
 			}
 		}
 	}
@@ -92,11 +77,21 @@ func LoadSpells(filename string) ([]Spell, error) {
 			continue // skip header
 		}
 		level, _ := strconv.Atoi(rec[1])
-		spells = append(spells, Spell{
+		spell := Spell{
 			Name:  rec[0],
 			Level: level,
-			Class: rec[2],
-		})
+		}
+		for _, class := range strings.Split(rec[2], ",") {
+			if class = strings.TrimSpace(class); class != "" {
+				spell.Class = append(spell.Class, class)
+			}
+		}
+		if len(rec) > 5 {
+			spell.School = rec[3]
+			spell.Ritual, _ = strconv.ParseBool(rec[4])
+			spell.Concentration, _ = strconv.ParseBool(rec[5])
+		}
+		spells = append(spells, spell)
 	}
 	return spells, nil
 }
@@ -105,58 +100,287 @@ func LoadSpells(filename string) ([]Spell, error) {
 func FilterSpellsByClass(spells []Spell, class string) []Spell {
 	var filtered []Spell
 	for _, s := range spells {
-		if strings.EqualFold(s.Class, class) {
+		if s.HasClass(class) {
 			filtered = append(filtered, s)
 		}
 	}
 	return filtered
 }
 
+// FindSpell finds a spell by name, ignoring case and punctuation
+func FindSpell(spells []Spell, name string) *Spell {
+	for _, s := range spells {
+		if lookup.Matches(s.Name, name) {
+			return &s
+		}
+	}
+	return nil
+}
+
+// SuggestSpells returns the names of the spells closest to a name that wasn't found
+func SuggestSpells(spells []Spell, name string) []string {
+	names := make([]string, 0, len(spells))
+	for _, s := range spells {
+		names = append(names, s.Name)
+	}
+	return lookup.Suggest(name, names, 3)
+}
+
 // CanCastSpells returns true if the caster type is not none
 func CanCastSpells(casterType CasterType) bool {
 	return casterType == CasterFull || casterType == CasterHalf || casterType == CasterPact || casterType == CasterKnown
 }
 
-// LearnSpell attempts to add a spell to the character's known spells
-func LearnSpell(cs *CharacterSpellcasting, spell Spell) string {
+// LearnSpell attempts to add a spell to the character's known spells and describes the outcome
+// for the CLI; see learnSpell for the rules
+func LearnSpell(cs *CharacterSpellcasting, spell Spell, class string, level int) string {
+	if err := learnSpell(cs, spell, class, level); err != nil {
+		return err.Error()
+	}
+	if spell.Level == 0 {
+		return "Learned cantrip " + strings.ToLower(spell.Name)
+	}
+	return "Learned spell " + strings.ToLower(spell.Name)
+}
+
+// learnSpell adds a spell to the character's known spells. Cantrips are learned by every caster
+// and count against the cantrips known; leveled spells are learned only by known-spell casters,
+// up to the spells known for the class level and the highest slot level.
+func learnSpell(cs *CharacterSpellcasting, spell Spell, class string, level int) error {
+	if !CanCastSpells(cs.CasterType) {
+		return fmt.Errorf("this class can't cast spells")
+	}
+	if HasSpell(cs, spell.Name) {
+		return fmt.Errorf("already learned this spell")
+	}
+	if spell.Level == 0 {
+		if limit := GetCantripsKnown(class, level); len(cs.Cantrips) >= limit {
+			return fmt.Errorf("already knows %d of %d cantrips; forget one first", len(cs.Cantrips), limit)
+		}
+		cs.Cantrips = append(cs.Cantrips, spell.Name)
+		return nil
+	}
 	switch cs.CasterType {
 	case CasterKnown, CasterPact:
-		for _, s := range cs.KnownSpells {
-			if strings.EqualFold(s, spell.Name) {
-				return "Already learned this spell"
-			}
+		if spell.Level > highestSlot(cs) {
+			return fmt.Errorf("the spell has higher level than the available spell slots")
+		}
+		if limit := GetSpellsKnown(class, level); len(cs.KnownSpells) >= limit {
+			return fmt.Errorf("already knows %d of %d spells; forget or replace one first", len(cs.KnownSpells), limit)
 		}
 		cs.KnownSpells = append(cs.KnownSpells, spell.Name)
-		return "Learned spell " + strings.ToLower(spell.Name)
-	case CasterFull, CasterHalf:
-		return "this class prepares spells and can't learn them"
+		return nil
 	default:
-		return "this class can't cast spells"
+		return fmt.Errorf("this class prepares spells and can't learn them")
+	}
+}
+
+// ForgetSpell removes a cantrip or known spell from the character
+func ForgetSpell(cs *CharacterSpellcasting, name string) string {
+	for _, list := range []*[]string{&cs.Cantrips, &cs.KnownSpells} {
+		for i, s := range *list {
+			if strings.EqualFold(s, strings.TrimSpace(name)) {
+				*list = append((*list)[:i], (*list)[i+1:]...)
+				return "Forgot spell " + strings.ToLower(s)
+			}
+		}
+	}
+	return fmt.Sprintf("%s is not a known spell", strings.ToLower(strings.TrimSpace(name)))
+}
+
+// ReplaceSpell swaps a known leveled spell for another, which the SRD allows once each time a
+// known-spell caster gains a level, so not before level 2. Cantrips can't be swapped either way.
+// On failure the old spell is kept.
+func ReplaceSpell(cs *CharacterSpellcasting, old string, spell Spell, class string, level int) error {
+	if cs.CasterType != CasterKnown && cs.CasterType != CasterPact {
+		return fmt.Errorf("only classes that learn spells can replace them")
+	}
+	if level < 2 {
+		return fmt.Errorf("a known spell can only be replaced after gaining a level")
+	}
+	if cs.ReplacedAt >= level {
+		return fmt.Errorf("already replaced a spell at level %d; the next replacement comes with the next level", cs.ReplacedAt)
+	}
+	if spell.Level == 0 {
+		return fmt.Errorf("cantrips can't be swapped on level-up")
+	}
+	index := -1
+	for i, s := range cs.KnownSpells {
+		if strings.EqualFold(s, strings.TrimSpace(old)) {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		for _, c := range cs.Cantrips {
+			if strings.EqualFold(c, strings.TrimSpace(old)) {
+				return fmt.Errorf("cantrips can't be swapped on level-up")
+			}
+		}
+		return fmt.Errorf("%s is not a known spell", strings.ToLower(strings.TrimSpace(old)))
+	}
+	previous := append([]string{}, cs.KnownSpells...)
+	cs.KnownSpells = append(cs.KnownSpells[:index], cs.KnownSpells[index+1:]...)
+	if err := learnSpell(cs, spell, class, level); err != nil {
+		cs.KnownSpells = previous
+		return err
 	}
+	cs.ReplacedAt = level
+	return nil
 }
 
-// PrepareSpell attempts to add a spell to the character's prepared spells
-func PrepareSpell(cs *CharacterSpellcasting, spell Spell) string {
+// PreparedLimit returns how many leveled spells a preparing class can have prepared: the spellcasting
+// ability modifier plus the class level, or half the level for paladins, and at least one
+func PreparedLimit(class string, level int, abilityMod int) int {
+	switch strings.ToLower(class) {
+	case "paladin":
+		return max(1, abilityMod+level/2)
+	case "cleric", "druid", "wizard":
+		return max(1, abilityMod+level)
+	default:
+		return 0
+	}
+}
+
+// PreparedCount returns how many prepared spells count against the limit; cantrips and
+// always-prepared spells don't
+func PreparedCount(cs *CharacterSpellcasting, spells []Spell) int {
+	count := 0
+	for _, name := range cs.PreparedSpells {
+		if IsAlwaysPrepared(cs, name) {
+			continue
+		}
+		if s := FindSpell(spells, name); s == nil || s.Level > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// IsAlwaysPrepared reports whether the spell is one of the subclass's always-prepared spells
+func IsAlwaysPrepared(cs *CharacterSpellcasting, name string) bool {
+	for _, s := range cs.AlwaysPrepared {
+		if strings.EqualFold(s, strings.TrimSpace(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// EffectivePrepared returns the always-prepared spells followed by the chosen prepared spells
+func EffectivePrepared(cs *CharacterSpellcasting) []string {
+	prepared := append([]string{}, cs.AlwaysPrepared...)
+	for _, s := range cs.PreparedSpells {
+		if !IsAlwaysPrepared(cs, s) {
+			prepared = append(prepared, s)
+		}
+	}
+	return prepared
+}
+
+// FormatPrepared returns the effective prepared spells, marking the always-prepared ones
+func FormatPrepared(cs *CharacterSpellcasting) string {
+	var names []string
+	for _, s := range EffectivePrepared(cs) {
+		if IsAlwaysPrepared(cs, s) {
+			s += " (always prepared)"
+		}
+		names = append(names, s)
+	}
+	return strings.Join(names, ", ")
+}
+
+// highestSlot returns the highest spell slot level the character has
+func highestSlot(cs *CharacterSpellcasting) int {
+	maxSlot := 0
+	for lvl := range cs.SpellSlots {
+		if lvl > maxSlot {
+			maxSlot = lvl
+		}
+	}
+	return maxSlot
+}
+
+// PrepareSpell attempts to add a spell to the character's prepared spells and describes the
+// outcome for the CLI; see prepareSpell for the rules
+func PrepareSpell(cs *CharacterSpellcasting, spell Spell, spells []Spell, limit int) string {
+	if err := prepareSpell(cs, spell, spells, limit); err != nil {
+		return err.Error()
+	}
+	return "Prepared spell " + strings.ToLower(spell.Name)
+}
+
+// prepareSpell adds a spell to the character's prepared spells, keeping leveled spells within
+// the limit. spells is the class spell list used to count them. Classes with a spellbook prepare
+// only spells written in it.
+func prepareSpell(cs *CharacterSpellcasting, spell Spell, spells []Spell, limit int) error {
 	switch cs.CasterType {
 	case CasterFull, CasterHalf:
-		if spell.Level == 0 {
-			cs.PreparedSpells = append(cs.PreparedSpells, spell.Name)
-			return "Prepared spell " + strings.ToLower(spell.Name)
+		if IsAlwaysPrepared(cs, spell.Name) {
+			return fmt.Errorf("this spell is always prepared")
 		}
-		maxSlot := 0
-		for lvl := range cs.SpellSlots {
-			if lvl > maxSlot {
-				maxSlot = lvl
+		for _, s := range cs.PreparedSpells {
+			if strings.EqualFold(s, spell.Name) {
+				return fmt.Errorf("already prepared this spell")
 			}
 		}
-		if spell.Level > maxSlot {
-			return "the spell has higher level than the available spell slots"
+		if spell.Level == 0 {
+			return fmt.Errorf("cantrips are learned, not prepared; use learn-spell")
+		}
+		if cs.Spellbook != nil && !InSpellbook(cs, spell.Name) {
+			return fmt.Errorf("%s is not in the spellbook", strings.ToLower(spell.Name))
+		}
+		if spell.Level > highestSlot(cs) {
+			return fmt.Errorf("the spell has higher level than the available spell slots")
+		}
+		if PreparedCount(cs, spells) >= limit {
+			return fmt.Errorf("already preparing %d of %d spells; unprepare one first", PreparedCount(cs, spells), limit)
 		}
 		cs.PreparedSpells = append(cs.PreparedSpells, spell.Name)
-		return "Prepared spell " + strings.ToLower(spell.Name)
+		return nil
 	case CasterKnown, CasterPact:
-		return "this class learns spells and can't prepare them"
+		return fmt.Errorf("this class learns spells and can't prepare them")
 	default:
-		return "this class can't cast spells"
+		return fmt.Errorf("this class can't cast spells")
+	}
+}
+
+// UnprepareSpell removes a spell from the character's prepared spells
+func UnprepareSpell(cs *CharacterSpellcasting, name string) string {
+	if IsAlwaysPrepared(cs, name) {
+		return fmt.Sprintf("%s is always prepared", strings.ToLower(strings.TrimSpace(name)))
+	}
+	for i, s := range cs.PreparedSpells {
+		if strings.EqualFold(s, strings.TrimSpace(name)) {
+			cs.PreparedSpells = append(cs.PreparedSpells[:i], cs.PreparedSpells[i+1:]...)
+			return "Unprepared spell " + strings.ToLower(s)
+		}
+	}
+	return fmt.Sprintf("%s is not prepared", strings.ToLower(strings.TrimSpace(name)))
+}
+
+// PrepareSet replaces the whole prepared list, as after a long rest. The new list must fit the
+// slot levels and the limit and can't repeat a spell; on error the old list is kept.
+func PrepareSet(cs *CharacterSpellcasting, set []Spell, spells []Spell, limit int) error {
+	if cs.CasterType != CasterFull && cs.CasterType != CasterHalf {
+		return fmt.Errorf("this class doesn't prepare spells")
+	}
+	leveled := 0
+	for _, spell := range set {
+		if spell.Level > 0 {
+			leveled++
+		}
+	}
+	if leveled > limit {
+		return fmt.Errorf("%d spells is more than the %d that can be prepared", leveled, limit)
+	}
+	previous := cs.PreparedSpells
+	cs.PreparedSpells = nil
+	for _, spell := range set {
+		if err := prepareSpell(cs, spell, spells, limit); err != nil {
+			cs.PreparedSpells = previous
+			return fmt.Errorf("%s: %v", strings.ToLower(spell.Name), err)
+		}
 	}
+	return nil
 }
@@ -0,0 +1,108 @@
+package spellcasting
+
+import (
+	"reflect"
+	"testing"
+)
+
+// sorcerer returns a level 2 sorcerer knowing two cantrips and two 1st level spells
+func sorcerer() *CharacterSpellcasting {
+	return &CharacterSpellcasting{
+		CasterType:  CasterKnown,
+		Cantrips:    []string{"Fire Bolt", "Light"},
+		KnownSpells: []string{"Magic Missile", "Shield"},
+		SpellSlots:  map[int]int{1: 3},
+	}
+}
+
+func TestReplaceSpell(t *testing.T) {
+	sleep := Spell{Name: "Sleep", Level: 1}
+	tests := []struct {
+		name   string
+		old    string
+		spell  Spell
+		level  int
+		prior  int // ReplacedAt before the swap
+		failed bool
+	}{
+		{"after gaining a level", "Shield", sleep, 2, 0, false},
+		{"ignores case", "magic missile", sleep, 2, 0, false},
+		{"not at level 1", "Shield", sleep, 1, 0, true},
+		{"once per level", "Shield", sleep, 2, 2, true},
+		{"next level again", "Shield", sleep, 3, 2, false},
+		{"cantrip target", "Fire Bolt", sleep, 2, 0, true},
+		{"cantrip replacement", "Shield", Spell{Name: "Mage Hand", Level: 0}, 2, 0, true},
+		{"unknown target", "Fireball", sleep, 2, 0, true},
+		{"above the highest slot", "Shield", Spell{Name: "Fireball", Level: 3}, 2, 0, true},
+	}
+	for _, tt := range tests {
+		cs := sorcerer()
+		cs.ReplacedAt = tt.prior
+		before := *sorcerer()
+		err := ReplaceSpell(cs, tt.old, tt.spell, "sorcerer", tt.level)
+		if tt.failed {
+			if err == nil {
+				t.Errorf("%s: ReplaceSpell succeeded", tt.name)
+			}
+			if !reflect.DeepEqual(cs.KnownSpells, before.KnownSpells) || !reflect.DeepEqual(cs.Cantrips, before.Cantrips) {
+				t.Errorf("%s: failed ReplaceSpell left %v and cantrips %v", tt.name, cs.KnownSpells, cs.Cantrips)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: ReplaceSpell failed: %v", tt.name, err)
+			continue
+		}
+		if HasSpell(cs, tt.old) || !HasSpell(cs, tt.spell.Name) || len(cs.KnownSpells) != 2 {
+			t.Errorf("%s: ReplaceSpell left %v", tt.name, cs.KnownSpells)
+		}
+		if cs.ReplacedAt != tt.level {
+			t.Errorf("%s: ReplacedAt = %d, want %d", tt.name, cs.ReplacedAt, tt.level)
+		}
+	}
+}
+
+func TestReplaceSpellPreparedCaster(t *testing.T) {
+	cs := &CharacterSpellcasting{CasterType: CasterFull, KnownSpells: []string{"Bless"}, SpellSlots: map[int]int{1: 3}}
+	if err := ReplaceSpell(cs, "Bless", Spell{Name: "Sanctuary", Level: 1}, "cleric", 3); err == nil {
+		t.Errorf("ReplaceSpell succeeded for a cleric")
+	}
+}
+
+func TestPrepareSet(t *testing.T) {
+	spells := []Spell{{Name: "Bless", Level: 1}, {Name: "Cure Wounds", Level: 1}, {Name: "Aid", Level: 2}, {Name: "Revivify", Level: 3}, {Name: "Light", Level: 0}}
+	newCleric := func() *CharacterSpellcasting {
+		return &CharacterSpellcasting{CasterType: CasterFull, PreparedSpells: []string{"Bless"}, SpellSlots: map[int]int{1: 4, 2: 2}}
+	}
+	tests := []struct {
+		name  string
+		set   []Spell
+		want  []string
+		fails bool
+	}{
+		{"replaces the list", []Spell{spells[1], spells[2]}, []string{"Cure Wounds", "Aid"}, false},
+		{"over the limit", []Spell{spells[0], spells[1], spells[2]}, nil, true},
+		{"above the highest slot", []Spell{spells[3]}, nil, true},
+		{"repeated spell", []Spell{spells[0], spells[0]}, nil, true},
+		{"cantrip", []Spell{spells[4]}, nil, true},
+	}
+	for _, tt := range tests {
+		cs := newCleric()
+		err := PrepareSet(cs, tt.set, spells, 2)
+		if tt.fails {
+			if err == nil {
+				t.Errorf("%s: PrepareSet succeeded", tt.name)
+			}
+			if !reflect.DeepEqual(cs.PreparedSpells, []string{"Bless"}) {
+				t.Errorf("%s: failed PrepareSet left %v", tt.name, cs.PreparedSpells)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(cs.PreparedSpells, tt.want) {
+			t.Errorf("%s: PrepareSet left %v, %v; want %v", tt.name, cs.PreparedSpells, err, tt.want)
+		}
+	}
+	if err := PrepareSet(sorcerer(), []Spell{spells[0]}, spells, 2); err == nil {
+		t.Errorf("PrepareSet succeeded for a sorcerer")
+	}
+}
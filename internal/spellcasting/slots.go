@@ -0,0 +1,192 @@
+package spellcasting
+
+import (
+	"encoding/json"
+	"fmt"
+	"modules/dndcharactersheet/internal/api"
+	characterModel "modules/dndcharactersheet/internal/character"
+	"modules/dndcharactersheet/internal/lookup"
+	"strings"
+)
+
+// ForCharacter decodes the character's stored spellcasting data, filling in the caster type and
+// spell slots for its class and level when they are missing. Slots with no tracked use are full.
+func ForCharacter(char *characterModel.Character) CharacterSpellcasting {
+	var cs CharacterSpellcasting
+	if char.Spellcasting != nil {
+		if data, err := json.Marshal(char.Spellcasting); err == nil {
+			_ = json.Unmarshal(data, &cs)
+		}
+	}
+	// the class decides the caster type, which also moves characters saved under an older type
+	if casterType, ok := CasterTypeByClass[strings.ToLower(char.Class)]; ok && cs.CasterType != casterType {
+		cs.CasterType = casterType
+		cs.SpellSlots = nil
+		cs.CurrentSlots = nil
+	}
+	if cs.CasterType == "" {
+		cs.CasterType = CasterNone
+	}
+	if cs.CasterType != CasterNone && len(cs.SpellSlots) == 0 {
+		cs.SpellSlots = GetDefaultSpellSlots(char.Class, char.Level)
+	}
+	if cs.CurrentSlots == nil {
+		RestoreSlots(&cs)
+	}
+	if UsesSpellbook(char.Class) && cs.Spellbook == nil {
+		cs.Spellbook = []string{}
+	}
+	return cs
+}
+
+// RestoreSlots refills every spell slot to its maximum
+func RestoreSlots(cs *CharacterSpellcasting) {
+	cs.CurrentSlots = make(map[int]int, len(cs.SpellSlots))
+	for lvl, max := range cs.SpellSlots {
+		cs.CurrentSlots[lvl] = max
+	}
+}
+
+// RemainingSlots returns how many slots of a level are left
+func RemainingSlots(cs *CharacterSpellcasting, level int) int {
+	remaining, ok := cs.CurrentSlots[level]
+	if !ok {
+		return cs.SpellSlots[level]
+	}
+	return remaining
+}
+
+// HasSpell reports whether the spell is among the character's cantrips, known or prepared spells
+func HasSpell(cs *CharacterSpellcasting, name string) bool {
+	for _, s := range append(append(append([]string{}, cs.Cantrips...), cs.KnownSpells...), EffectivePrepared(cs)...) {
+		if strings.EqualFold(s, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// LowestSlot returns the lowest slot level with a slot left that can cast a spell of the level, or 0
+func LowestSlot(cs *CharacterSpellcasting, spellLevel int) int {
+	for lvl := max(spellLevel, 1); lvl <= 9; lvl++ {
+		if RemainingSlots(cs, lvl) > 0 {
+			return lvl
+		}
+	}
+	return 0
+}
+
+// Cast checks that the spell is known or prepared and uses a slot of the given level for it,
+// or the lowest slot left that can cast it when slot is 0. It returns the slot level used;
+// cantrips don't use a slot.
+func Cast(cs *CharacterSpellcasting, spell Spell, slot int) (int, error) {
+	if !CanCastSpells(cs.CasterType) {
+		return 0, fmt.Errorf("this class can't cast spells")
+	}
+	if !HasSpell(cs, spell.Name) {
+		if cs.CasterType == CasterKnown || cs.CasterType == CasterPact {
+			return 0, fmt.Errorf("%s is not a known spell", strings.ToLower(spell.Name))
+		}
+		return 0, fmt.Errorf("%s is not prepared", strings.ToLower(spell.Name))
+	}
+	if spell.Level == 0 {
+		return 0, nil
+	}
+	if slot == 0 {
+		if slot = LowestSlot(cs, spell.Level); slot == 0 {
+			return 0, fmt.Errorf("no spell slots left to cast %s", strings.ToLower(spell.Name))
+		}
+	}
+	if slot < spell.Level {
+		return 0, fmt.Errorf("%s is level %d and can't be cast with a level %d slot", strings.ToLower(spell.Name), spell.Level, slot)
+	}
+	if cs.SpellSlots[slot] == 0 {
+		return 0, fmt.Errorf("no level %d spell slots", slot)
+	}
+	if RemainingSlots(cs, slot) == 0 {
+		return 0, fmt.Errorf("no level %d spell slots left", slot)
+	}
+	if cs.CurrentSlots == nil {
+		RestoreSlots(cs)
+	}
+	cs.CurrentSlots[slot] = RemainingSlots(cs, slot) - 1
+	return slot, nil
+}
+
+// RitualSource names where each class with the Ritual Casting feature casts rituals from
+var RitualSource = map[string]string{
+	"wizard": "spellbook",
+	"cleric": "prepared",
+	"druid":  "prepared",
+	"bard":   "known",
+}
+
+// CastRitual checks that the class can cast the spell as a ritual, which uses no slot: wizards
+// cast rituals written in the spellbook, prepared or not, clerics and druids those they have
+// prepared and bards those they know
+func CastRitual(cs *CharacterSpellcasting, spell Spell, class string) error {
+	if !CanCastSpells(cs.CasterType) {
+		return fmt.Errorf("this class can't cast spells")
+	}
+	if !spell.Ritual {
+		return fmt.Errorf("%s is not a ritual", strings.ToLower(spell.Name))
+	}
+	switch RitualSource[strings.ToLower(class)] {
+	case "spellbook":
+		if !InSpellbook(cs, spell.Name) {
+			return fmt.Errorf("%s is not in the spellbook", strings.ToLower(spell.Name))
+		}
+	case "prepared":
+		if !containsSpell(EffectivePrepared(cs), spell.Name) {
+			return fmt.Errorf("%s is not prepared", strings.ToLower(spell.Name))
+		}
+	case "known":
+		if !containsSpell(cs.KnownSpells, spell.Name) {
+			return fmt.Errorf("%s is not a known spell", strings.ToLower(spell.Name))
+		}
+	default:
+		return fmt.Errorf("this class can't cast rituals")
+	}
+	return nil
+}
+
+// containsSpell reports whether the list holds the spell name, ignoring case
+func containsSpell(list []string, name string) bool {
+	for _, s := range list {
+		if strings.EqualFold(s, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// EnrichSpell fills in the spell's ritual tag and casting time from the API when it is reachable;
+// otherwise the ritual tag from the SRD spell list stays and the casting time is unknown
+func EnrichSpell(spell *Spell) {
+	enriched, err := api.GetSpell(lookup.Index(spell.Name))
+	if err != nil || enriched == nil || enriched.Name == "" {
+		return
+	}
+	spell.Ritual = enriched.Ritual
+	spell.CastingTime = enriched.CastingTime
+}
+
+// RitualCastingTime returns how long casting a spell as a ritual takes: 10 minutes on top of
+// its normal casting time
+func RitualCastingTime(castingTime string) string {
+	if castingTime == "" {
+		return "10 minutes longer than normal"
+	}
+	return castingTime + " + 10 minutes"
+}
+
+// Concentrate makes the character concentrate on the spell if it needs concentration, which ends
+// concentration on any previous spell. It returns the spell that ended, or "".
+func Concentrate(char *characterModel.Character, spell Spell) string {
+	if !spell.Concentration {
+		return ""
+	}
+	ended := char.Concentration
+	char.Concentration = spell.Name
+	return ended
+}
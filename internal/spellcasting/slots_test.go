@@ -0,0 +1,107 @@
+package spellcasting
+
+import "testing"
+
+// cleric returns a cleric with Bless and Spiritual Weapon prepared, one 2nd level slot used
+func cleric() *CharacterSpellcasting {
+	return &CharacterSpellcasting{
+		CasterType:     CasterFull,
+		Cantrips:       []string{"Sacred Flame"},
+		PreparedSpells: []string{"Bless", "Spiritual Weapon"},
+		SpellSlots:     map[int]int{1: 4, 2: 3},
+		CurrentSlots:   map[int]int{1: 0, 2: 2},
+	}
+}
+
+func TestCast(t *testing.T) {
+	bless := Spell{Name: "Bless", Level: 1}
+	tests := []struct {
+		name  string
+		spell Spell
+		slot  int
+		used  int
+		left  map[int]int
+		fails bool
+	}{
+		{"cantrip uses no slot", Spell{Name: "Sacred Flame"}, 0, 0, map[int]int{1: 0, 2: 2}, false},
+		{"lowest slot left", bless, 0, 2, map[int]int{1: 0, 2: 1}, false},
+		{"chosen slot", Spell{Name: "Spiritual Weapon", Level: 2}, 2, 2, map[int]int{1: 0, 2: 1}, false},
+		{"no slot of that level left", bless, 1, 0, nil, true},
+		{"slot below the spell level", Spell{Name: "Spiritual Weapon", Level: 2}, 1, 0, nil, true},
+		{"no slots of that level", bless, 3, 0, nil, true},
+		{"not prepared", Spell{Name: "Cure Wounds", Level: 1}, 0, 0, nil, true},
+	}
+	for _, tt := range tests {
+		cs := cleric()
+		used, err := Cast(cs, tt.spell, tt.slot)
+		if tt.fails {
+			if err == nil {
+				t.Errorf("%s: Cast succeeded with slot %d", tt.name, used)
+			}
+			if cs.CurrentSlots[1] != 0 || cs.CurrentSlots[2] != 2 {
+				t.Errorf("%s: failed Cast changed the slots to %v", tt.name, cs.CurrentSlots)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: Cast failed: %v", tt.name, err)
+			continue
+		}
+		if used != tt.used {
+			t.Errorf("%s: Cast used slot %d, want %d", tt.name, used, tt.used)
+		}
+		for lvl, left := range tt.left {
+			if RemainingSlots(cs, lvl) != left {
+				t.Errorf("%s: %d level %d slots left, want %d", tt.name, RemainingSlots(cs, lvl), lvl, left)
+			}
+		}
+	}
+}
+
+func TestRestoreSlots(t *testing.T) {
+	cs := cleric()
+	RestoreSlots(cs)
+	if RemainingSlots(cs, 1) != 4 || RemainingSlots(cs, 2) != 3 {
+		t.Errorf("RestoreSlots left %v", cs.CurrentSlots)
+	}
+	// slots never tracked count as full
+	fresh := &CharacterSpellcasting{CasterType: CasterFull, PreparedSpells: []string{"Bless"}, SpellSlots: map[int]int{1: 2}}
+	if RemainingSlots(fresh, 1) != 2 || LowestSlot(fresh, 1) != 1 {
+		t.Errorf("untracked slots: %d left, lowest %d", RemainingSlots(fresh, 1), LowestSlot(fresh, 1))
+	}
+	if _, err := Cast(fresh, Spell{Name: "Bless", Level: 1}, 0); err != nil || RemainingSlots(fresh, 1) != 1 {
+		t.Errorf("casting with untracked slots left %d, %v", RemainingSlots(fresh, 1), err)
+	}
+}
+
+func TestCastRitual(t *testing.T) {
+	alarm := Spell{Name: "Alarm", Level: 1, Ritual: true}
+	wizard := &CharacterSpellcasting{CasterType: CasterFull, Spellbook: []string{"Alarm"}, SpellSlots: map[int]int{1: 2}}
+	bard := &CharacterSpellcasting{CasterType: CasterKnown, KnownSpells: []string{"Silence"}, SpellSlots: map[int]int{2: 2}}
+	sorcerer := &CharacterSpellcasting{CasterType: CasterKnown, KnownSpells: []string{"Detect Magic"}, SpellSlots: map[int]int{1: 2}}
+	tests := []struct {
+		name  string
+		cs    *CharacterSpellcasting
+		class string
+		spell Spell
+		fails bool
+	}{
+		{"wizard from the spellbook", wizard, "wizard", alarm, false},
+		{"wizard without the spell", wizard, "wizard", Spell{Name: "Comprehend Languages", Level: 1, Ritual: true}, true},
+		{"not a ritual", wizard, "wizard", Spell{Name: "Shield", Level: 1}, true},
+		{"cleric prepared", cleric(), "cleric", Spell{Name: "Bless", Level: 1, Ritual: true}, false},
+		{"cleric unprepared", cleric(), "cleric", Spell{Name: "Detect Magic", Level: 1, Ritual: true}, true},
+		{"bard known", bard, "bard", Spell{Name: "Silence", Level: 2, Ritual: true}, false},
+		{"no ritual casting", sorcerer, "sorcerer", Spell{Name: "Detect Magic", Level: 1, Ritual: true}, true},
+	}
+	for _, tt := range tests {
+		slots := RemainingSlots(tt.cs, tt.spell.Level)
+		err := CastRitual(tt.cs, tt.spell, tt.class)
+		if (err != nil) != tt.fails {
+			t.Errorf("%s: CastRitual = %v", tt.name, err)
+		}
+		if RemainingSlots(tt.cs, tt.spell.Level) != slots {
+			t.Errorf("%s: a ritual used a spell slot", tt.name)
+		}
+	}
+}
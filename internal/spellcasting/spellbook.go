@@ -0,0 +1,102 @@
+package spellcasting
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Spellbook rules from the SRD: a wizard starts with six 1st-level spells and writes two more
+// each time they gain a level. Copying a spell found on a scroll or in another book takes
+// 2 hours and 50 gp for each level of the spell.
+const (
+	SpellbookStartingSpells = 6
+	SpellbookSpellsPerLevel = 2
+	CopyHoursPerLevel       = 2
+	CopyCostPerLevel        = 50 * 100 // copper pieces
+)
+
+// UsesSpellbook reports whether the class prepares its spells from a spellbook
+func UsesSpellbook(class string) bool {
+	return strings.EqualFold(class, "wizard")
+}
+
+// SpellbookAllowance returns how many spells a wizard of the level has written for free by leveling
+func SpellbookAllowance(level int) int {
+	if level < 1 {
+		return 0
+	}
+	return SpellbookStartingSpells + SpellbookSpellsPerLevel*(level-1)
+}
+
+// CopyCost returns the copper and hours it takes to copy a spell into a spellbook
+func CopyCost(spell Spell) (int, int) {
+	return CopyCostPerLevel * spell.Level, CopyHoursPerLevel * spell.Level
+}
+
+// InSpellbook reports whether the spell is written in the character's spellbook
+func InSpellbook(cs *CharacterSpellcasting, name string) bool {
+	for _, s := range cs.Spellbook {
+		if strings.EqualFold(s, strings.TrimSpace(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// LeveledSpellbookCount returns how many spellbook spells were gained by leveling rather than copied
+func LeveledSpellbookCount(cs *CharacterSpellcasting) int {
+	return len(cs.Spellbook) - len(cs.CopiedSpells)
+}
+
+// checkSpellbookEntry checks that a spell can be written in the spellbook: it must be a leveled
+// spell the wizard has slots for and not already be in the book
+func checkSpellbookEntry(cs *CharacterSpellcasting, spell Spell) error {
+	if cs.Spellbook == nil {
+		return fmt.Errorf("this class doesn't keep a spellbook")
+	}
+	if spell.Level == 0 {
+		return fmt.Errorf("cantrips aren't written in a spellbook; use learn-spell")
+	}
+	if InSpellbook(cs, spell.Name) {
+		return fmt.Errorf("%s is already in the spellbook", strings.ToLower(spell.Name))
+	}
+	if spell.Level > highestSlot(cs) {
+		return fmt.Errorf("the spell has higher level than the available spell slots")
+	}
+	return nil
+}
+
+// AddToSpellbook writes one of the spells gained by leveling into the spellbook
+func AddToSpellbook(cs *CharacterSpellcasting, spell Spell, level int) error {
+	if err := checkSpellbookEntry(cs, spell); err != nil {
+		return err
+	}
+	if limit := SpellbookAllowance(level); LeveledSpellbookCount(cs) >= limit {
+		return fmt.Errorf("already wrote %d of %d spells gained by level; copy a scroll to add more", LeveledSpellbookCount(cs), limit)
+	}
+	cs.Spellbook = append(cs.Spellbook, spell.Name)
+	return nil
+}
+
+// CopySpell writes a spell found on a scroll or in another spellbook into the spellbook.
+// It isn't limited by level; paying for it is up to the caller, see CopyCost.
+func CopySpell(cs *CharacterSpellcasting, spell Spell) error {
+	if err := checkSpellbookEntry(cs, spell); err != nil {
+		return err
+	}
+	cs.Spellbook = append(cs.Spellbook, spell.Name)
+	cs.CopiedSpells = append(cs.CopiedSpells, spell.Name)
+	return nil
+}
+
+// FormatSpellbook returns the spellbook with the spells gained by level and those copied
+func FormatSpellbook(cs *CharacterSpellcasting, level int) string {
+	if cs == nil || cs.Spellbook == nil {
+		return ""
+	}
+	list := "none"
+	if len(cs.Spellbook) > 0 {
+		list = strings.Join(cs.Spellbook, ", ")
+	}
+	return fmt.Sprintf("Spellbook (%d/%d by level, %d copied): %s\n", LeveledSpellbookCount(cs), SpellbookAllowance(level), len(cs.CopiedSpells), list)
+}
@@ -1,32 +1,88 @@
 package main
 
 import (
-	"encoding/json"
+	"bufio"
 	"flag"
 	"fmt"
+	"math/rand"
 	backgroundModel "modules/dndcharactersheet/internal/background"
 	characterModel "modules/dndcharactersheet/internal/character"
 	classModel "modules/dndcharactersheet/internal/class"
 	"modules/dndcharactersheet/internal/combat"
+	"modules/dndcharactersheet/internal/condition"
+	"modules/dndcharactersheet/internal/currency"
+	"modules/dndcharactersheet/internal/dice"
+	"modules/dndcharactersheet/internal/encounter"
 	"modules/dndcharactersheet/internal/equipment"
+	"modules/dndcharactersheet/internal/lookup"
+	"modules/dndcharactersheet/internal/magicitem"
+	raceModel "modules/dndcharactersheet/internal/race"
+	"modules/dndcharactersheet/internal/rest"
 	"modules/dndcharactersheet/internal/spellcasting"
 	"modules/dndcharactersheet/internal/storage"
 	"os"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
 func usage() {
 	fmt.Printf(`Usage:
   %s create -name CHARACTER_NAME -race RACE -class CLASS -level N -str N -dex N -con N -int N -wis N -cha N
+      [-background B] [-subclass S] [-mainhand W -offhand W -armor A -shield S [-force]] [-starting-equipment [-choices a,b,... -weapons W,...] | -starting-gold]
   %s view -name CHARACTER_NAME
+  %s subclass -name CHARACTER_NAME [-set SUBCLASS]
   %s list
   %s delete -name CHARACTER_NAME
-  %s equip -name CHARACTER_NAME -weapon WEAPON_NAME -slot SLOT
-  %s equip -name CHARACTER_NAME -armor ARMOR_NAME
-  %s equip -name CHARACTER_NAME -shield SHIELD_NAME
-  %s learn-spell -name CHARACTER_NAME -spell SPELL_NAME
+  %s equip -name CHARACTER_NAME -weapon WEAPON_NAME -slot SLOT [-force]
+  %s equip -name CHARACTER_NAME -armor ARMOR_NAME [-force]
+  %s equip -name CHARACTER_NAME -shield SHIELD_NAME [-force]
+  %s unequip -name CHARACTER_NAME -slot SLOT
+  %s swap -name CHARACTER_NAME [-weapon WEAPON_NAME -slot SLOT | -armor ARMOR_NAME | -shield SHIELD_NAME] [-force]
+  %s add-item -name CHARACTER_NAME -item ITEM_NAME [-quantity N]
+  %s remove-item -name CHARACTER_NAME -item ITEM_NAME [-quantity N]
+  %s inventory -name CHARACTER_NAME
+  %s wallet -name CHARACTER_NAME [-deposit AMOUNT] [-withdraw AMOUNT]
+  %s buy -name CHARACTER_NAME -item ITEM_NAME [-quantity N]
+  %s sell -name CHARACTER_NAME -item ITEM_NAME [-quantity N]
+  %s ledger [-name CHARACTER_NAME]
+  %s fire -name CHARACTER_NAME [-slot SLOT] [-shots N]
+  %s recover-ammo -name CHARACTER_NAME
+  %s magic-item add|remove|equip|unequip|attune|unattune -name CHARACTER_NAME -item ITEM_NAME [-slot SLOT]
+  %s magic-items
+  %s alias [-item NAME -index API_INDEX]
+  %s learn-spell -name CHARACTER_NAME -spell SPELL_NAME [-replace KNOWN_SPELL]
+  %s forget-spell -name CHARACTER_NAME -spell SPELL_NAME
   %s prepare-spell -name CHARACTER_NAME -spell SPELL_NAME 
-`, os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0])
+  %s unprepare-spell -name CHARACTER_NAME -spell SPELL_NAME
+  %s prepare-set -name CHARACTER_NAME -spells SPELL_NAME,SPELL_NAME,...
+  %s spells [-class CLASS] [-level N] [-school SCHOOL] [-ritual] [-search TEXT]
+  %s auto-spells -name CHARACTER_NAME [-seed N] [-prefer SCHOOL_OR_TAG,... [-weight N]]
+  %s spellbook -name CHARACTER_NAME [-add SPELL_NAME | -copy SPELL_NAME]
+  %s cast -name CHARACTER_NAME -spell SPELL_NAME [-slot N | -ritual]
+  %s damage -name CHARACTER_NAME -amount N [-crit] [-save TOTAL | -roll]
+  %s heal -name CHARACTER_NAME -amount N
+  %s death-save -name CHARACTER_NAME [-seed N]
+  %s short-rest -name CHARACTER_NAME [-dice N] [-average]
+  %s long-rest -name CHARACTER_NAME
+  %s use-resource -name CHARACTER_NAME -resource RESOURCE_NAME [-amount N]
+  %s roll -expr EXPRESSION [-seed N]
+  %s check -name CHARACTER_NAME -skill SKILL [-adv | -dis] [-seed N]
+  %s save -name CHARACTER_NAME -ability ABILITY [-adv | -dis] [-seed N]
+  %s attack -name CHARACTER_NAME -weapon mainhand|offhand [-ac N] [-adv | -dis] [-seed N]
+  %s condition add|remove -name CHARACTER_NAME -condition CONDITION [-levels N]
+  %s conditions
+  %s encounter start -characters NAME,NAME,... [-monsters NAME[*COUNT]:AC:HP[:DEX],...] [-seed N] [-force]
+  %s encounter status|next|end
+  %s encounter damage -target NAME -amount N [-crit]
+  %s encounter condition -target NAME [-add CONDITION | -remove CONDITION] [-levels N]
+`, os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0],
+		os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0],
+		os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0],
+		os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0],
+		os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0])
 }
 
 func main() {
@@ -35,6 +91,9 @@ func main() {
 		os.Exit(1)
 	}
 	cmd := os.Args[1]
+	if _, err := lookup.Aliases(); err != nil {
+		fmt.Printf("warning: %v\n", err)
+	}
 
 	switch cmd {
 	case "create":
@@ -52,11 +111,17 @@ func main() {
 		wis := createCmd.Int("wis", 10, "wisdom")
 		cha := createCmd.Int("cha", 10, "charisma")
 		background := createCmd.String("background", "acolyte", "background")
+		subclass := createCmd.String("subclass", "", "subclass, e.g. \"Life Domain\" (from the level the class chooses one)")
 		skills := createCmd.String("skill_proficiencies", "", "skill proficiencies (comma separated)")
 		mainhand := createCmd.String("mainhand", "", "main hand weapon")
 		offhand := createCmd.String("offhand", "", "off hand weapon")
 		armorFlag := createCmd.String("armor", "", "armor name")
 		shieldFlag := createCmd.String("shield", "", "shield name")
+		force := createCmd.Bool("force", false, "equip gear the character isn't proficient with")
+		startingEquipment := createCmd.Bool("starting-equipment", false, "take the class and background starting equipment")
+		choices := createCmd.String("choices", "", "starting equipment options in order, e.g. a,b,a (asked interactively when omitted)")
+		weapons := createCmd.String("weapons", "", "weapons for \"any ... weapon\" starting equipment, comma separated")
+		startingGold := createCmd.Bool("starting-gold", false, "roll the class starting gold instead of taking equipment")
 
 		err := createCmd.Parse(os.Args[2:])
 		if err != nil {
@@ -120,14 +185,91 @@ func main() {
 			Background:         selectedBackground.Name,
 			Proficiency:        profiencyBonus,
 			SkillProficiencies: combinedSkills,
-			MainHand:           strings.ToLower(strings.TrimSpace(*mainhand)),
-			OffHand:            strings.ToLower(strings.TrimSpace(*offhand)),
-			Armor:              strings.ToLower(strings.TrimSpace(*armorFlag)),
-			Shield:             strings.ToLower(strings.TrimSpace(*shieldFlag)),
 		}
 
 		// Apply racial ability score bonuses
 		characterService.ApplyRacialBonuses(&char)
+		if sc := spellcasting.AssignSpellcasting(char.Class, char.Level); sc.CasterType != spellcasting.CasterNone {
+			char.Spellcasting = sc
+		}
+		if *subclass != "" {
+			sub, err := selectedClass.ChooseSubclass(*subclass, char.Level)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			char.Subclass = sub.Name
+		}
+
+		// Armor and weapon proficiencies and speed from class and race
+		err = loadClassAndRaceData(&char, characterService)
+		if err != nil {
+			fmt.Println("Could not load proficiencies:", err)
+			os.Exit(1)
+		}
+
+		// Equip the requested gear after checking it against the equipment catalog
+		equipmentList, err := equipment.LoadEquipmentFromCSV("5e-SRD-Equipment.csv")
+		if err != nil {
+			fmt.Println("Could not load equipment:", err)
+			os.Exit(1)
+		}
+		for _, gear := range []struct{ slot, item string }{
+			{equipment.SlotMainHand, *mainhand},
+			{equipment.SlotOffHand, *offhand},
+			{equipment.SlotArmor, *armorFlag},
+			{equipment.SlotShield, *shieldFlag},
+		} {
+			item := strings.TrimSpace(gear.item)
+			if item == "" {
+				continue
+			}
+			item, err := checkCatalogItem(equipmentList, gear.slot, item)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			confirmProficiency(&char, gear.slot, item, *force)
+			if err := equipment.Equip(&char, gear.slot, item); err != nil {
+				fmt.Printf("cannot equip %s: %v\n", item, err)
+				os.Exit(1)
+			}
+		}
+
+		if *startingEquipment && *startingGold {
+			fmt.Println("choose either -starting-equipment or -starting-gold")
+			os.Exit(2)
+		}
+		if *startingEquipment {
+			var chooser equipment.Chooser = promptChooser{in: bufio.NewReader(os.Stdin)}
+			if *choices != "" || *weapons != "" {
+				chooser = &flagChooser{picks: splitList(*choices), weapons: splitList(*weapons)}
+			}
+			items, err := equipment.ResolveStartingEquipment(selectedClass, selectedBackground, chooser)
+			if err != nil {
+				fmt.Println("Could not choose starting equipment:", err)
+				os.Exit(1)
+			}
+			if err := equipment.GrantStartingEquipment(&char, items, equipmentList); err != nil {
+				fmt.Println("Could not grant starting equipment:", err)
+				os.Exit(1)
+			}
+			if selectedBackground.Gold > 0 {
+				char.Wallet.GP += selectedBackground.Gold
+				currency.Record(&char, "deposit", "", 0, selectedBackground.Gold*currency.GP)
+			}
+		}
+		if *startingGold {
+			rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+			gold, err := currency.RollStartingGold(selectedClass.StartingGold, rng)
+			if err != nil {
+				fmt.Println("Could not roll starting gold:", err)
+				os.Exit(1)
+			}
+			char.Wallet.GP += gold
+			currency.Record(&char, "deposit", "", 0, gold*currency.GP)
+			fmt.Printf("rolled %s: %d gp starting gold\n", selectedClass.StartingGold, gold)
+		}
 
 		// Set ability modifiers based on final ability scores
 		char.StrMod = characterService.AbilityModifier(char.Str)
@@ -136,12 +278,10 @@ func main() {
 		char.IntMod = characterService.AbilityModifier(char.Int)
 		char.WisMod = characterService.AbilityModifier(char.Wis)
 		char.ChaMod = characterService.AbilityModifier(char.Cha)
-		// Set armor class, initiative, and passive perception using backend calculation
-		char.ArmorClass = combat.CalculateArmorClass(&char, characterService)
-		char.Initiative = combat.CalculateInitiative(&char, characterService)
-		char.PassivePerception = combat.CalculatePassivePerception(&char, characterService)
+		// Set armor class, initiative, passive perception, speed and attacks using backend calculation
+		recalculateCombatStats(&char, characterService)
 
-		// Set spell attack bonus if applicable
+		// Set spell attack bonus and spell slots if applicable
 		spellStats := combat.CalculateSpellcastingStats(&char, characterService)
 		char.SpellAttackBonus = spellStats.SpellAttackBonus
 
@@ -175,26 +315,27 @@ func main() {
 
 		// fmt.Printf("Character: %+v\n", char)
 
-		// Unmarshal the character's spellcasting data (interface{}) into the correct struct
-		var sc spellcasting.CharacterSpellcasting
-		spellcastingBytes, err := json.Marshal(char.Spellcasting)
-		if err == nil {
-			_ = json.Unmarshal(spellcastingBytes, &sc)
-		}
-		// If spell slots are missing and the character is a caster, auto-generate them
+		// Decode the character's spellcasting data, generating spell slots for casters that lack them
+		sc := spellcasting.ForCharacter(&char)
 		casterType, ok := spellcasting.CasterTypeByClass[strings.ToLower(char.Class)]
-		if ok && casterType != spellcasting.CasterNone && len(sc.SpellSlots) == 0 {
-			sc.SpellSlots = spellcasting.GetDefaultSpellSlots(strings.ToLower(char.Class), char.Level)
-		}
 
 		// Prints character sheet in CLI
 		characterService := characterModel.NewCharacterService()
+		if missingClassAndRaceData(&char) {
+			_ = loadClassAndRaceData(&char, characterService)
+		}
+		if equipmentList, err := equipment.LoadEquipmentFromCSV("5e-SRD-Equipment.csv"); err == nil {
+			char.CarriedWeight = equipment.CarriedWeight(&char, equipmentList)
+		}
 		ac := combat.CalculateArmorClass(&char, characterService)
 		initiative := combat.CalculateInitiative(&char, characterService)
 		passivePerception := combat.CalculatePassivePerception(&char, characterService)
 		equipDisplay := equipment.GetFormattedEquipment(&char)
 		fmt.Printf("Name: %s\n", char.Name)
 		fmt.Printf("Class: %s\n", strings.ToLower(char.Class))
+		if char.Subclass != "" {
+			fmt.Printf("Subclass: %s\n", char.Subclass)
+		}
 		fmt.Printf("Race: %s\n", strings.ToLower(char.Race))
 		fmt.Printf("Background: %s\n", char.Background)
 		fmt.Printf("Level: %d\n", char.Level)
@@ -207,6 +348,7 @@ func main() {
 		fmt.Printf("  CHA: %d (%+d)\n", char.Cha, characterService.AbilityModifier(char.Cha))
 		fmt.Printf("Proficiency bonus: +%d\n", char.Proficiency)
 		fmt.Printf("Skill proficiencies: %s\n", strings.Join(char.SkillProficiencies, ", "))
+		fmt.Print(combat.FormatSavingThrows(&char, characterService))
 		if equipDisplay.MainHand != "" {
 			fmt.Printf("Main hand: %s\n", equipDisplay.MainHand)
 		}
@@ -219,29 +361,151 @@ func main() {
 		if equipDisplay.Shield != "" {
 			fmt.Printf("Shield: %s\n", equipDisplay.Shield)
 		}
+		fmt.Print(combat.FormatAttacks(combat.CalculateAttacks(&char, characterService)))
+		fmt.Print(magicitem.FormatMagicItems(&char))
+		for _, note := range combat.ArmorProficiencyNotes(&char) {
+			fmt.Printf("Note: %s\n", note)
+		}
 		if ok && casterType != spellcasting.CasterNone && char.Name != "Branric Ironwall" {
 			slotsStr := spellcasting.FormatSpellSlots(&sc, char.Class, char.Level)
 			if slotsStr != "" {
 				fmt.Print(slotsStr)
 			}
 			// Print cantrips using spellcasting helper
-			cantripsStr := spellcasting.FormatCantrips(&sc)
+			cantripsStr := spellcasting.FormatCantrips(&sc, char.Class, char.Level)
 			if cantripsStr != "" {
 				fmt.Print(cantripsStr)
 			}
+			if sc.CasterType == spellcasting.CasterKnown || sc.CasterType == spellcasting.CasterPact {
+				fmt.Printf("Known spells (%d/%d): %s\n", len(sc.KnownSpells), spellcasting.GetSpellsKnown(char.Class, char.Level), displayOrNone(strings.Join(sc.KnownSpells, ", ")))
+			}
+			fmt.Print(spellcasting.FormatSpellbook(&sc, char.Level))
+			if sc.CasterType == spellcasting.CasterFull || sc.CasterType == spellcasting.CasterHalf {
+				classSpells, _ := loadClassSpells(char.Class)
+				limit := spellcasting.PreparedLimit(char.Class, char.Level, combat.CalculateSpellcastingStats(&char, characterService).AbilityMod)
+				fmt.Printf("Prepared spells (%d/%d): %s\n", spellcasting.PreparedCount(&sc, classSpells), limit, displayOrNone(spellcasting.FormatPrepared(&sc)))
+			}
 			// Print spellcasting stats using combat helper
 			fmt.Print(combat.FormatSpellcastingStats(&char, characterService))
+			if spells, err := spellcasting.LoadSpells("5e-SRD-Spells.csv"); err == nil {
+				fmt.Print(combat.FormatSpellDamageTable(combat.CalculateSpellDamageTable(&char, characterService, spells)))
+			}
+			if reason := combat.SpellcastingBlocked(&char); reason != "" {
+				fmt.Printf("Spellcasting blocked: %s\n", reason)
+			}
 		}
 		if char.Name != "Merry Brandybuck" && char.Name != "Pippin Took" && char.Name != "Obi-Wan Kenobi" && char.Name != "Anakin Skywalker" {
 			fmt.Printf("Armor class: %d\n", ac)
 			fmt.Printf("Initiative bonus: %d\n", initiative)
 			fmt.Printf("Passive perception: %d\n", passivePerception)
 		}
+		if char.MaxHP > 0 {
+			fmt.Printf("Hit points: %d/%d", char.CurrentHP, condition.MaxHP(&char))
+			if maxHP := condition.MaxHP(&char); maxHP != char.MaxHP {
+				fmt.Printf(" (maximum %d halved by exhaustion)", char.MaxHP)
+			}
+			if char.TempHP > 0 {
+				fmt.Printf(" (+%d temporary)", char.TempHP)
+			}
+			fmt.Printf("\nHit dice: %d/%d (d%d)\n", char.HitDice, char.Level, char.HitDie)
+		}
+		for _, note := range condition.Notes(&char) {
+			fmt.Printf("Condition: %s\n", note)
+		}
+		if char.Concentration != "" {
+			fmt.Printf("Concentrating on: %s\n", strings.ToLower(char.Concentration))
+		}
+		if state := combat.FormatDeathState(&char); state != "" {
+			fmt.Printf("Status: %s\n", state)
+		}
+		fmt.Print(rest.FormatResources(&char))
+		var slowed []string
+		if penalty := combat.ArmorSpeedPenalty(&char); penalty != "" {
+			slowed = append(slowed, penalty)
+		}
+		if _, reason := condition.Speed(&char, 0); reason != "" {
+			slowed = append(slowed, reason)
+		}
+		if len(slowed) > 0 {
+			fmt.Printf("Speed: %d ft (%s)\n", combat.CalculateSpeed(&char), strings.Join(slowed, "; "))
+		} else {
+			fmt.Printf("Speed: %d ft\n", combat.CalculateSpeed(&char))
+		}
+		if combat.HasStealthDisadvantage(&char) {
+			fmt.Printf("Stealth: disadvantage (%s)\n", char.Armor)
+		}
+		if len(char.Inventory) > 0 {
+			items := 0
+			for _, item := range char.Inventory {
+				items += item.Quantity
+			}
+			fmt.Printf("Inventory: %d item(s), %s lb carried\n", items, equipment.FormatWeight(char.CarriedWeight))
+		}
+		for _, note := range combat.EncumbranceNotes(&char) {
+			fmt.Printf("Note: %s\n", note)
+		}
 
 		// Set spell attack bonus for frontend
 		spellStats := combat.CalculateSpellcastingStats(&char, characterService)
 		char.SpellAttackBonus = spellStats.SpellAttackBonus
 
+	case "subclass":
+		subclassCmd := flag.NewFlagSet("subclass", flag.ExitOnError)
+		name := subclassCmd.String("name", "", "character name (required)")
+		set := subclassCmd.String("set", "", "subclass to take, e.g. \"Oath of Devotion\"")
+		subclassCmd.Parse(os.Args[2:])
+		if *name == "" {
+			fmt.Println("-name is required")
+			subclassCmd.Usage()
+			os.Exit(2)
+		}
+		characterStorage := storage.NewSingleFileStorage("characters.json")
+		char, err := characterStorage.Load(*name)
+		if err != nil {
+			fmt.Printf("character \"%s\" not found\n", *name)
+			os.Exit(1)
+		}
+		classes, err := classModel.LoadClasses("classes.json")
+		if err != nil {
+			fmt.Println("Could not load classes:", err)
+			os.Exit(1)
+		}
+		var selectedClass classModel.Class
+		for _, cls := range classes {
+			if strings.EqualFold(cls.Name, char.Class) {
+				selectedClass = cls
+				break
+			}
+		}
+		if *set == "" {
+			fmt.Printf("Subclass: %s\n", displayOrNone(char.Subclass))
+			for _, sub := range selectedClass.Subclasses {
+				fmt.Printf("  %s (from level %d)\n", sub.Name, sub.Level)
+			}
+			return
+		}
+		sub, err := selectedClass.ChooseSubclass(*set, char.Level)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		char.Subclass = sub.Name
+		characterService := characterModel.NewCharacterService()
+		if err := loadClassAndRaceData(&char, characterService); err != nil {
+			fmt.Println("Could not load class data:", err)
+			os.Exit(1)
+		}
+		recalculateCombatStats(&char, characterService)
+		err = characterStorage.Save(char)
+		if err != nil {
+			fmt.Printf("error saving character: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s takes the %s\n", char.Name, sub.Name)
+		if spells := sub.AlwaysPreparedAt(char.Level); len(spells) > 0 {
+			fmt.Printf("Always prepared: %s\n", strings.Join(spells, ", "))
+		}
+
 	case "list":
 		characterStorage := storage.NewSingleFileStorage("characters.json")
 		summaries, err := characterStorage.List()
@@ -298,6 +562,7 @@ func main() {
 		armor := equipCmd.String("armor", "", "armor name")
 		shield := equipCmd.String("shield", "", "shield name")
 		slot := equipCmd.String("slot", "", "slot for weapon (e.g., \"main hand\")")
+		force := equipCmd.Bool("force", false, "equip even without proficiency")
 		equipCmd.Parse(os.Args[2:])
 
 		if *name == "" {
@@ -321,42 +586,37 @@ func main() {
 			os.Exit(1)
 		}
 
+		characterService := characterModel.NewCharacterService()
+		if missingClassAndRaceData(&char) {
+			err = loadClassAndRaceData(&char, characterService)
+			if err != nil {
+				fmt.Printf("could not load proficiencies: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
 		// Equip weapon
 		if *weapon != "" {
 			item := equipment.FindEquipmentByName(equipmentList, *weapon)
 			if item == nil {
-				fmt.Printf("weapon '%s' not found\n", *weapon)
+				fmt.Printf("weapon '%s' not found%s\n", *weapon, lookup.DidYouMean(equipment.SuggestEquipment(equipmentList, *weapon)))
 				os.Exit(1)
 			}
 
-			// respect slot and normalize
-			s := strings.ToLower(strings.TrimSpace(*slot))
-			sNorm := "main hand"
-			switch s {
-			case "main hand", "main", "mh":
-				sNorm = "main hand"
-			case "off hand", "off", "oh":
-				sNorm = "off hand"
-			default:
-				sNorm = "main hand"
+			// respect slot and normalize, defaulting to the main hand
+			sNorm := equipment.NormalizeSlot(*slot)
+			if sNorm != equipment.SlotOffHand {
+				sNorm = equipment.SlotMainHand
 			}
 
 			itemName := strings.ToLower(item.Name)
-			// prevent overwriting an occupied slot
-			switch sNorm {
-			case "main hand":
-				if char.MainHand != "" {
-					fmt.Printf("%s already occupied\n", sNorm)
-					os.Exit(1)
-				}
-				char.MainHand = itemName
-			case "off hand":
-				if char.OffHand != "" {
-					fmt.Printf("%s already occupied\n", sNorm)
-					os.Exit(1)
-				}
-				char.OffHand = itemName
+			confirmProficiency(&char, sNorm, itemName, *force)
+			// prevent overwriting an occupied slot or a hand that is already in use
+			if err := equipment.Equip(&char, sNorm, itemName); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
 			}
+			recalculateCombatStats(&char, characterService)
 
 			// Save
 			err = characterStorage.Save(char)
@@ -373,25 +633,23 @@ func main() {
 		if *armor != "" {
 			item := equipment.FindEquipmentByName(equipmentList, *armor)
 			if item == nil {
-				fmt.Printf("armor '%s' not found\n", *armor)
+				fmt.Printf("armor '%s' not found%s\n", *armor, lookup.DidYouMean(equipment.SuggestEquipment(equipmentList, *armor)))
 				os.Exit(1)
 			}
-			if char.Armor != "" {
-				fmt.Printf("armor already occupied\n")
+			itemName := strings.ToLower(item.Name)
+			if err := equipment.CanEquip(&char, equipment.SlotArmor, itemName); err != nil {
+				fmt.Println(err)
 				os.Exit(1)
 			}
-			char.Armor = strings.ToLower(item.Name)
-			// Recalculate armor class, initiative, and passive perception
-			characterService := characterModel.NewCharacterService()
-			char.ArmorClass = combat.CalculateArmorClass(&char, characterService)
-			char.Initiative = combat.CalculateInitiative(&char, characterService)
-			char.PassivePerception = combat.CalculatePassivePerception(&char, characterService)
+			confirmProficiency(&char, equipment.SlotArmor, itemName, *force)
+			_ = equipment.Equip(&char, equipment.SlotArmor, itemName)
+			recalculateCombatStats(&char, characterService)
 			err = characterStorage.Save(char)
 			if err != nil {
 				fmt.Printf("error saving character: %v\n", err)
 				os.Exit(1)
 			}
-			fmt.Printf("Equipped armor %s\n", strings.ToLower(item.Name))
+			fmt.Printf("Equipped armor %s\n", itemName)
 			return
 		}
 
@@ -399,126 +657,1756 @@ func main() {
 		if *shield != "" {
 			item := equipment.FindEquipmentByName(equipmentList, *shield)
 			if item == nil {
-				fmt.Printf("shield '%s' not found\n", *shield)
+				fmt.Printf("shield '%s' not found%s\n", *shield, lookup.DidYouMean(equipment.SuggestEquipment(equipmentList, *shield)))
 				os.Exit(1)
 			}
-			if char.Shield != "" {
-				fmt.Printf("shield already occupied\n")
+			itemName := strings.ToLower(item.Name)
+			if err := equipment.CanEquip(&char, equipment.SlotShield, itemName); err != nil {
+				fmt.Println(err)
 				os.Exit(1)
 			}
-			char.Shield = strings.ToLower(item.Name)
-			// Recalculate armor class, initiative, and passive perception
-			characterService := characterModel.NewCharacterService()
-			char.ArmorClass = combat.CalculateArmorClass(&char, characterService)
-			char.Initiative = combat.CalculateInitiative(&char, characterService)
-			char.PassivePerception = combat.CalculatePassivePerception(&char, characterService)
+			confirmProficiency(&char, equipment.SlotShield, itemName, *force)
+			_ = equipment.Equip(&char, equipment.SlotShield, itemName)
+			recalculateCombatStats(&char, characterService)
 			err = characterStorage.Save(char)
 			if err != nil {
 				fmt.Printf("error saving character: %v\n", err)
 				os.Exit(1)
 			}
-			fmt.Printf("Equipped shield %s\n", strings.ToLower(item.Name))
+			fmt.Printf("Equipped shield %s\n", itemName)
 			return
 		}
 
-	case "learn-spell":
-		learnCmd := flag.NewFlagSet("learn-spell", flag.ExitOnError)
-		name := learnCmd.String("name", "", "character name (required)")
-		spellName := learnCmd.String("spell", "", "spell name (required)")
-		learnCmd.Parse(os.Args[2:])
-		if *name == "" || *spellName == "" {
-			fmt.Println("-name and -spell are required")
+	case "unequip":
+		unequipCmd := flag.NewFlagSet("unequip", flag.ExitOnError)
+		name := unequipCmd.String("name", "", "character name (required)")
+		slot := unequipCmd.String("slot", "", "slot to empty: main hand, off hand, armor or shield (required)")
+		unequipCmd.Parse(os.Args[2:])
+
+		sNorm := equipment.NormalizeSlot(*slot)
+		if *name == "" || sNorm == "" {
+			fmt.Println("-name and a valid -slot are required")
+			unequipCmd.Usage()
 			os.Exit(2)
 		}
+
 		characterStorage := storage.NewSingleFileStorage("characters.json")
 		char, err := characterStorage.Load(*name)
 		if err != nil {
 			fmt.Printf("character \"%s\" not found\n", *name)
 			os.Exit(1)
 		}
-		// Always assign spellcasting for the character's class and level
-		sc := spellcasting.AssignSpellcasting(char.Class, char.Level)
-		char.Spellcasting = sc
-		if sc.CasterType == spellcasting.CasterNone {
-			fmt.Println(spellcasting.LearnSpell(&sc, spellcasting.Spell{Name: *spellName}))
-			os.Exit(0)
+
+		removed, err := equipment.Unequip(&char, sNorm)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
 		}
-		spells, err := spellcasting.LoadSpells("5e-SRD-Spells.csv")
+		recalculateCombatStats(&char, characterModel.NewCharacterService())
+		err = characterStorage.Save(char)
 		if err != nil {
-			fmt.Println("Could not load spells:", err)
+			fmt.Printf("error saving character: %v\n", err)
 			os.Exit(1)
 		}
-		var foundSpell *spellcasting.Spell
-		for _, s := range spells {
-			if strings.EqualFold(s.Name, *spellName) && strings.Contains(strings.ToLower(s.Class), strings.ToLower(char.Class)) {
-				foundSpell = &s
-				break
+		fmt.Printf("Unequipped %s from %s\n", removed, sNorm)
+
+	case "swap":
+		swapCmd := flag.NewFlagSet("swap", flag.ExitOnError)
+		name := swapCmd.String("name", "", "character name (required)")
+		weapon := swapCmd.String("weapon", "", "weapon to swap in")
+		armor := swapCmd.String("armor", "", "armor to swap in")
+		shield := swapCmd.String("shield", "", "shield to swap in")
+		slot := swapCmd.String("slot", "", "slot for weapon (e.g., \"main hand\")")
+		force := swapCmd.Bool("force", false, "equip even without proficiency")
+		swapCmd.Parse(os.Args[2:])
+
+		if *name == "" {
+			fmt.Println("Error: -name is required")
+			swapCmd.Usage()
+			os.Exit(1)
+		}
+
+		characterStorage := storage.NewSingleFileStorage("characters.json")
+		char, err := characterStorage.Load(*name)
+		if err != nil {
+			fmt.Printf("character \"%s\" not found\n", *name)
+			os.Exit(1)
+		}
+		characterService := characterModel.NewCharacterService()
+		if missingClassAndRaceData(&char) {
+			err = loadClassAndRaceData(&char, characterService)
+			if err != nil {
+				fmt.Printf("could not load proficiencies: %v\n", err)
+				os.Exit(1)
 			}
 		}
-		if foundSpell == nil {
-			fmt.Printf("spell '%s' not found for class %s\n", *spellName, char.Class)
+
+		// Without an item, swap the main hand and off hand weapons
+		if *weapon == "" && *armor == "" && *shield == "" {
+			if err := equipment.SwapHands(&char); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			recalculateCombatStats(&char, characterService)
+			err = characterStorage.Save(char)
+			if err != nil {
+				fmt.Printf("error saving character: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("Swapped hands: main hand %s, off hand %s\n", displayOrNone(char.MainHand), displayOrNone(char.OffHand))
+			return
+		}
+
+		equipmentList, err := equipment.LoadEquipmentFromCSV("5e-SRD-Equipment.csv")
+		if err != nil {
+			fmt.Printf("could not load equipment: %v\n", err)
 			os.Exit(1)
 		}
-		result := spellcasting.LearnSpell(&sc, *foundSpell)
-		char.Spellcasting = sc
+
+		sNorm, itemArg := equipment.SlotArmor, *armor
+		switch {
+		case *weapon != "":
+			sNorm, itemArg = equipment.NormalizeSlot(*slot), *weapon
+			if sNorm != equipment.SlotOffHand {
+				sNorm = equipment.SlotMainHand
+			}
+		case *shield != "":
+			sNorm, itemArg = equipment.SlotShield, *shield
+		}
+		item := equipment.FindEquipmentByName(equipmentList, itemArg)
+		if item == nil {
+			fmt.Printf("item '%s' not found%s\n", itemArg, lookup.DidYouMean(equipment.SuggestEquipment(equipmentList, itemArg)))
+			os.Exit(1)
+		}
+		itemName := strings.ToLower(item.Name)
+		confirmProficiency(&char, sNorm, itemName, *force)
+		removed, err := equipment.Replace(&char, sNorm, itemName)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		recalculateCombatStats(&char, characterService)
 		err = characterStorage.Save(char)
 		if err != nil {
 			fmt.Printf("error saving character: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Println(result)
-		return
+		if removed != "" {
+			fmt.Printf("Swapped %s for %s in %s\n", removed, itemName, sNorm)
+		} else {
+			fmt.Printf("Equipped %s to %s\n", itemName, sNorm)
+		}
 
-	case "prepare-spell":
-		prepareCmd := flag.NewFlagSet("prepare-spell", flag.ExitOnError)
-		name := prepareCmd.String("name", "", "character name (required)")
-		spellName := prepareCmd.String("spell", "", "spell name (required)")
-		prepareCmd.Parse(os.Args[2:])
-		if *name == "" || *spellName == "" {
-			fmt.Println("-name and -spell are required")
+	case "add-item", "remove-item":
+		itemCmd := flag.NewFlagSet(cmd, flag.ExitOnError)
+		name := itemCmd.String("name", "", "character name (required)")
+		itemFlag := itemCmd.String("item", "", "item name from the SRD equipment list (required)")
+		quantity := itemCmd.Int("quantity", 1, "number of items")
+		itemCmd.Parse(os.Args[2:])
+
+		if *name == "" || *itemFlag == "" {
+			fmt.Println("-name and -item are required")
+			itemCmd.Usage()
 			os.Exit(2)
 		}
+
 		characterStorage := storage.NewSingleFileStorage("characters.json")
 		char, err := characterStorage.Load(*name)
 		if err != nil {
 			fmt.Printf("character \"%s\" not found\n", *name)
 			os.Exit(1)
 		}
-		// Always assign spellcasting for the character's class and level
-		sc := spellcasting.AssignSpellcasting(char.Class, char.Level)
-		char.Spellcasting = sc
-		if sc.CasterType == spellcasting.CasterNone {
-			fmt.Println(spellcasting.PrepareSpell(&sc, spellcasting.Spell{Name: *spellName}))
-			os.Exit(0)
-		}
-		spells, err := spellcasting.LoadSpells("5e-SRD-Spells.csv")
+
+		equipmentList, err := equipment.LoadEquipmentFromCSV("5e-SRD-Equipment.csv")
 		if err != nil {
-			fmt.Println("Could not load spells:", err)
+			fmt.Printf("could not load equipment: %v\n", err)
 			os.Exit(1)
 		}
-		var foundSpell *spellcasting.Spell
-		for _, s := range spells {
-			if strings.EqualFold(s.Name, *spellName) && strings.Contains(strings.ToLower(s.Class), strings.ToLower(char.Class)) {
-				foundSpell = &s
-				break
+
+		itemName := strings.ToLower(strings.TrimSpace(*itemFlag))
+		if cmd == "add-item" {
+			item := equipment.FindEquipmentByName(equipmentList, *itemFlag)
+			if item == nil {
+				fmt.Printf("item '%s' not found%s\n", *itemFlag, lookup.DidYouMean(equipment.SuggestEquipment(equipmentList, *itemFlag)))
+				os.Exit(1)
 			}
+			itemName = strings.ToLower(item.Name)
+			err = equipment.AddItem(&char, itemName, *quantity)
+		} else {
+			err = equipment.RemoveItem(&char, itemName, *quantity)
 		}
-		if foundSpell == nil {
-			fmt.Printf("spell '%s' not found for class %s\n", *spellName, char.Class)
+		if err != nil {
+			fmt.Println(err)
 			os.Exit(1)
 		}
-		result := spellcasting.PrepareSpell(&sc, *foundSpell)
-		char.Spellcasting = sc
+
+		recalculateCombatStats(&char, characterModel.NewCharacterService())
 		err = characterStorage.Save(char)
 		if err != nil {
 			fmt.Printf("error saving character: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Println(result)
-		return
+		if cmd == "add-item" {
+			fmt.Printf("Added %dx %s\n", *quantity, itemName)
+		} else {
+			fmt.Printf("Removed %dx %s\n", *quantity, itemName)
+		}
 
-	default:
-		usage()
-		os.Exit(2)
+	case "inventory":
+		inventoryCmd := flag.NewFlagSet("inventory", flag.ExitOnError)
+		name := inventoryCmd.String("name", "", "character name (required)")
+		inventoryCmd.Parse(os.Args[2:])
+
+		if *name == "" {
+			fmt.Println("Error: -name is required")
+			inventoryCmd.Usage()
+			os.Exit(1)
+		}
+
+		characterStorage := storage.NewSingleFileStorage("characters.json")
+		char, err := characterStorage.Load(*name)
+		if err != nil {
+			fmt.Printf("character \"%s\" not found\n", *name)
+			os.Exit(1)
+		}
+
+		equipmentList, err := equipment.LoadEquipmentFromCSV("5e-SRD-Equipment.csv")
+		if err != nil {
+			fmt.Printf("could not load equipment: %v\n", err)
+			os.Exit(1)
+		}
+
+		char.CarriedWeight = equipment.CarriedWeight(&char, equipmentList)
+		fmt.Print(equipment.FormatInventory(&char, equipmentList))
+		fmt.Printf("Carried weight: %s lb (capacity %d lb)\n", equipment.FormatWeight(char.CarriedWeight), 15*char.Str)
+		if level := combat.Encumbrance(&char); level != combat.EncumbranceNone {
+			fmt.Printf("Encumbrance: %s (speed %d ft)\n", level, combat.CalculateSpeed(&char))
+		}
+
+	case "wallet":
+		walletCmd := flag.NewFlagSet("wallet", flag.ExitOnError)
+		name := walletCmd.String("name", "", "character name (required)")
+		deposit := walletCmd.String("deposit", "", "coins to add, e.g. \"15 gp\"")
+		withdraw := walletCmd.String("withdraw", "", "coins to remove, e.g. \"5 sp\"")
+		walletCmd.Parse(os.Args[2:])
+
+		if *name == "" {
+			fmt.Println("Error: -name is required")
+			walletCmd.Usage()
+			os.Exit(1)
+		}
+
+		characterStorage := storage.NewSingleFileStorage("characters.json")
+		char, err := characterStorage.Load(*name)
+		if err != nil {
+			fmt.Printf("character \"%s\" not found\n", *name)
+			os.Exit(1)
+		}
+
+		if *deposit != "" || *withdraw != "" {
+			if *deposit != "" {
+				amount, err := currency.Deposit(&char.Wallet, *deposit)
+				if err != nil {
+					fmt.Println(err)
+					os.Exit(1)
+				}
+				currency.Record(&char, "deposit", "", 0, amount)
+			}
+			if *withdraw != "" {
+				amount, err := currency.ParseCost(*withdraw)
+				if err != nil {
+					fmt.Println(err)
+					os.Exit(1)
+				}
+				if err := currency.Pay(&char.Wallet, amount); err != nil {
+					fmt.Println(err)
+					os.Exit(1)
+				}
+				currency.Record(&char, "withdraw", "", 0, amount)
+			}
+			err = characterStorage.Save(char)
+			if err != nil {
+				fmt.Printf("error saving character: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		fmt.Printf("Wallet: %s\n", currency.FormatWallet(char.Wallet))
+
+	case "buy", "sell":
+		tradeCmd := flag.NewFlagSet(cmd, flag.ExitOnError)
+		name := tradeCmd.String("name", "", "character name (required)")
+		itemFlag := tradeCmd.String("item", "", "item name from the SRD equipment list (required)")
+		quantity := tradeCmd.Int("quantity", 1, "number of items")
+		tradeCmd.Parse(os.Args[2:])
+
+		if *name == "" || *itemFlag == "" || *quantity <= 0 {
+			fmt.Println("-name, -item and a positive -quantity are required")
+			tradeCmd.Usage()
+			os.Exit(2)
+		}
+
+		characterStorage := storage.NewSingleFileStorage("characters.json")
+		char, err := characterStorage.Load(*name)
+		if err != nil {
+			fmt.Printf("character \"%s\" not found\n", *name)
+			os.Exit(1)
+		}
+
+		equipmentList, err := equipment.LoadEquipmentFromCSV("5e-SRD-Equipment.csv")
+		if err != nil {
+			fmt.Printf("could not load equipment: %v\n", err)
+			os.Exit(1)
+		}
+
+		itemName := strings.ToLower(strings.TrimSpace(*itemFlag))
+		if item := equipment.FindEquipmentByName(equipmentList, *itemFlag); item != nil {
+			itemName = strings.ToLower(item.Name)
+		}
+		price, ok := equipment.PriceOf(equipmentList, itemName, *quantity)
+		if !ok {
+			fmt.Printf("no price found for '%s'%s\n", *itemFlag, lookup.DidYouMean(equipment.SuggestEquipment(equipmentList, *itemFlag)))
+			os.Exit(1)
+		}
+
+		if cmd == "buy" {
+			if err := currency.Pay(&char.Wallet, price); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			_ = equipment.AddItem(&char, itemName, *quantity)
+		} else {
+			// Equipment sells for half its price
+			price /= 2
+			if err := equipment.RemoveItem(&char, itemName, *quantity); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			currency.Receive(&char.Wallet, price)
+		}
+		currency.Record(&char, cmd, itemName, *quantity, price)
+
+		recalculateCombatStats(&char, characterModel.NewCharacterService())
+		err = characterStorage.Save(char)
+		if err != nil {
+			fmt.Printf("error saving character: %v\n", err)
+			os.Exit(1)
+		}
+		if cmd == "buy" {
+			fmt.Printf("Bought %dx %s for %s\n", *quantity, itemName, currency.FormatCopper(price))
+		} else {
+			fmt.Printf("Sold %dx %s for %s\n", *quantity, itemName, currency.FormatCopper(price))
+		}
+		fmt.Printf("Wallet: %s\n", currency.FormatWallet(char.Wallet))
+
+	case "ledger":
+		ledgerCmd := flag.NewFlagSet("ledger", flag.ExitOnError)
+		name := ledgerCmd.String("name", "", "character name (all characters if empty)")
+		ledgerCmd.Parse(os.Args[2:])
+
+		characterStorage := storage.NewSingleFileStorage("characters.json")
+		names := []string{*name}
+		if *name == "" {
+			summaries, err := characterStorage.List()
+			if err != nil {
+				fmt.Printf("Error listing characters: %v\n", err)
+				os.Exit(1)
+			}
+			names = nil
+			for _, summary := range summaries {
+				names = append(names, summary.Name)
+			}
+		}
+		for _, n := range names {
+			char, err := characterStorage.Load(n)
+			if err != nil {
+				fmt.Printf("character \"%s\" not found\n", n)
+				os.Exit(1)
+			}
+			fmt.Print(currency.FormatLedger(&char))
+		}
+
+	case "fire":
+		fireCmd := flag.NewFlagSet("fire", flag.ExitOnError)
+		name := fireCmd.String("name", "", "character name (required)")
+		slot := fireCmd.String("slot", "main hand", "slot of the ranged weapon")
+		shots := fireCmd.Int("shots", 1, "number of shots")
+		fireCmd.Parse(os.Args[2:])
+
+		if *name == "" || *shots <= 0 {
+			fmt.Println("-name and a positive -shots are required")
+			fireCmd.Usage()
+			os.Exit(2)
+		}
+
+		characterStorage := storage.NewSingleFileStorage("characters.json")
+		char, err := characterStorage.Load(*name)
+		if err != nil {
+			fmt.Printf("character \"%s\" not found\n", *name)
+			os.Exit(1)
+		}
+
+		sNorm := equipment.NormalizeSlot(*slot)
+		weaponName := char.MainHand
+		switch sNorm {
+		case equipment.SlotMainHand:
+		case equipment.SlotOffHand:
+			weaponName = char.OffHand
+		default:
+			fmt.Printf("unknown slot %q: weapons are fired from the main hand or off hand\n", *slot)
+			os.Exit(2)
+		}
+		if weaponName == "" {
+			fmt.Printf("no weapon equipped in the %s\n", *slot)
+			os.Exit(1)
+		}
+
+		ammo, err := equipment.ConsumeAmmunition(&char, weaponName, *shots)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		characterService := characterModel.NewCharacterService()
+		recalculateCombatStats(&char, characterService)
+		err = characterStorage.Save(char)
+		if err != nil {
+			fmt.Printf("error saving character: %v\n", err)
+			os.Exit(1)
+		}
+		attack := combat.CalculateWeaponAttack(&char, characterService, weaponName, sNorm)
+		fmt.Printf("Fired %d %s from %s: %+d to hit, %s\n", *shots, ammo, weaponName, attack.AttackBonus, attack.Damage)
+		fmt.Printf("%d %s left\n", equipment.CountItem(&char, ammo), ammo)
+
+	case "recover-ammo":
+		recoverCmd := flag.NewFlagSet("recover-ammo", flag.ExitOnError)
+		name := recoverCmd.String("name", "", "character name (required)")
+		recoverCmd.Parse(os.Args[2:])
+
+		if *name == "" {
+			fmt.Println("Error: -name is required")
+			recoverCmd.Usage()
+			os.Exit(1)
+		}
+
+		characterStorage := storage.NewSingleFileStorage("characters.json")
+		char, err := characterStorage.Load(*name)
+		if err != nil {
+			fmt.Printf("character \"%s\" not found\n", *name)
+			os.Exit(1)
+		}
+
+		// After the battle, half of the spent ammunition can be recovered
+		recovered := equipment.RecoverAmmunition(&char)
+		recalculateCombatStats(&char, characterModel.NewCharacterService())
+		err = characterStorage.Save(char)
+		if err != nil {
+			fmt.Printf("error saving character: %v\n", err)
+			os.Exit(1)
+		}
+		if len(recovered) == 0 {
+			fmt.Println("No ammunition to recover")
+			return
+		}
+		ammoNames := make([]string, 0, len(recovered))
+		for ammo := range recovered {
+			ammoNames = append(ammoNames, ammo)
+		}
+		sort.Strings(ammoNames)
+		for _, ammo := range ammoNames {
+			fmt.Printf("Recovered %d %s (%d in inventory)\n", recovered[ammo], ammo, equipment.CountItem(&char, ammo))
+		}
+
+	case "magic-item":
+		if len(os.Args) < 3 {
+			fmt.Println("magic-item needs an action: add, remove, equip, unequip, attune or unattune")
+			os.Exit(2)
+		}
+		action := os.Args[2]
+		magicCmd := flag.NewFlagSet("magic-item "+action, flag.ExitOnError)
+		name := magicCmd.String("name", "", "character name (required)")
+		itemFlag := magicCmd.String("item", "", "magic item name (required)")
+		slot := magicCmd.String("slot", "", "weapon slot for magic weapons (e.g., \"main hand\")")
+		magicCmd.Parse(os.Args[3:])
+
+		if *name == "" || *itemFlag == "" {
+			fmt.Println("-name and -item are required")
+			magicCmd.Usage()
+			os.Exit(2)
+		}
+
+		characterStorage := storage.NewSingleFileStorage("characters.json")
+		char, err := characterStorage.Load(*name)
+		if err != nil {
+			fmt.Printf("character \"%s\" not found\n", *name)
+			os.Exit(1)
+		}
+
+		itemName := strings.ToLower(strings.TrimSpace(*itemFlag))
+		switch action {
+		case "add":
+			catalog, err := magicitem.LoadMagicItems("magic-items.json")
+			if err != nil {
+				fmt.Println("Could not load magic items:", err)
+				os.Exit(1)
+			}
+			item, err := magicitem.FindMagicItem(catalog, *itemFlag)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			if err := magicitem.Acquire(&char, *item); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			itemName = strings.ToLower(item.Name)
+		case "remove":
+			err = magicitem.Remove(&char, itemName)
+		case "equip":
+			err = magicitem.Equip(&char, itemName, equipment.NormalizeSlot(*slot))
+		case "unequip":
+			err = magicitem.Unequip(&char, itemName)
+		case "attune":
+			err = magicitem.Attune(&char, itemName)
+		case "unattune":
+			err = magicitem.Unattune(&char, itemName)
+		default:
+			fmt.Printf("unknown magic-item action '%s'\n", action)
+			os.Exit(2)
+		}
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		recalculateCombatStats(&char, characterModel.NewCharacterService())
+		err = characterStorage.Save(char)
+		if err != nil {
+			fmt.Printf("error saving character: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s: %s %s\n", char.Name, action, itemName)
+		fmt.Print(magicitem.FormatMagicItems(&char))
+
+	case "magic-items":
+		catalog, err := magicitem.LoadMagicItems("magic-items.json")
+		if err != nil {
+			fmt.Println("Could not load magic items:", err)
+			os.Exit(1)
+		}
+		fmt.Println("Magic items:")
+		for _, it := range catalog {
+			line := fmt.Sprintf("  %s (%s %s)", it.Name, it.Rarity, it.Type)
+			if it.RequiresAttunement {
+				line += ", requires attunement"
+			}
+			if it.Homebrew {
+				line += ", homebrew"
+			}
+			fmt.Println(line)
+		}
+
+	case "alias":
+		aliasCmd := flag.NewFlagSet("alias", flag.ExitOnError)
+		item := aliasCmd.String("item", "", "item or spell name as written in the CSV files or by players")
+		index := aliasCmd.String("index", "", "API index the name should resolve to, e.g. plate-armor")
+		aliasCmd.Parse(os.Args[2:])
+
+		if *item != "" || *index != "" {
+			if err := lookup.AddAlias(*item, *index); err != nil {
+				fmt.Println("Could not add alias:", err)
+				os.Exit(1)
+			}
+			fmt.Printf("%s now resolves to %s\n", *item, lookup.Index(*item))
+			return
+		}
+		aliases, _ := lookup.Aliases()
+		keys := make([]string, 0, len(aliases))
+		for k := range aliases {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		fmt.Println("Aliases:")
+		for _, k := range keys {
+			fmt.Printf("  %s -> %s\n", k, aliases[k])
+		}
+
+	case "learn-spell", "forget-spell":
+		learnCmd := flag.NewFlagSet(cmd, flag.ExitOnError)
+		name := learnCmd.String("name", "", "character name (required)")
+		spellName := learnCmd.String("spell", "", "spell name (required)")
+		replace := learnCmd.String("replace", "", "known spell to swap for the new one after gaining a level (learn-spell)")
+		learnCmd.Parse(os.Args[2:])
+		if *name == "" || *spellName == "" {
+			fmt.Println("-name and -spell are required")
+			os.Exit(2)
+		}
+		characterStorage := storage.NewSingleFileStorage("characters.json")
+		char, err := characterStorage.Load(*name)
+		if err != nil {
+			fmt.Printf("character \"%s\" not found\n", *name)
+			os.Exit(1)
+		}
+		// Keep the spells and slots already tracked for the character
+		sc := spellcasting.ForCharacter(&char)
+		char.Spellcasting = sc
+		if sc.CasterType == spellcasting.CasterNone {
+			fmt.Println(spellcasting.LearnSpell(&sc, spellcasting.Spell{Name: *spellName}, char.Class, char.Level))
+			os.Exit(0)
+		}
+
+		var result string
+		if cmd == "forget-spell" {
+			result = spellcasting.ForgetSpell(&sc, *spellName)
+		} else {
+			classSpells, err := loadClassSpells(char.Class)
+			if err != nil {
+				fmt.Println("Could not load spells:", err)
+				os.Exit(1)
+			}
+			foundSpell := spellcasting.FindSpell(classSpells, *spellName)
+			if foundSpell == nil {
+				fmt.Printf("spell '%s' not found for class %s%s\n", *spellName, char.Class, lookup.DidYouMean(spellcasting.SuggestSpells(classSpells, *spellName)))
+				os.Exit(1)
+			}
+			if *replace != "" {
+				if err := spellcasting.ReplaceSpell(&sc, *replace, *foundSpell, char.Class, char.Level); err != nil {
+					fmt.Println(err)
+					os.Exit(1)
+				}
+				result = fmt.Sprintf("Replaced %s with %s", strings.ToLower(*replace), strings.ToLower(foundSpell.Name))
+			} else {
+				result = spellcasting.LearnSpell(&sc, *foundSpell, char.Class, char.Level)
+			}
+		}
+		char.Spellcasting = sc
+		recalculateCombatStats(&char, characterModel.NewCharacterService())
+		err = characterStorage.Save(char)
+		if err != nil {
+			fmt.Printf("error saving character: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(result)
+		return
+
+	case "prepare-spell", "unprepare-spell", "prepare-set":
+		prepareCmd := flag.NewFlagSet(cmd, flag.ExitOnError)
+		name := prepareCmd.String("name", "", "character name (required)")
+		spellName := prepareCmd.String("spell", "", "spell name (prepare-spell, unprepare-spell)")
+		spellList := prepareCmd.String("spells", "", "comma separated spell names to prepare instead of the current list (prepare-set)")
+		prepareCmd.Parse(os.Args[2:])
+		if *name == "" || (cmd == "prepare-set") == (*spellName != "") {
+			if cmd == "prepare-set" {
+				fmt.Println("-name and -spells are required")
+			} else {
+				fmt.Println("-name and -spell are required")
+			}
+			os.Exit(2)
+		}
+		characterStorage := storage.NewSingleFileStorage("characters.json")
+		char, err := characterStorage.Load(*name)
+		if err != nil {
+			fmt.Printf("character \"%s\" not found\n", *name)
+			os.Exit(1)
+		}
+		// Keep the spells and slots already tracked for the character
+		sc := spellcasting.ForCharacter(&char)
+		char.Spellcasting = sc
+		if sc.CasterType == spellcasting.CasterNone {
+			fmt.Println(spellcasting.PrepareSpell(&sc, spellcasting.Spell{Name: *spellName}, nil, 0))
+			os.Exit(0)
+		}
+		classSpells, err := loadClassSpells(char.Class)
+		if err != nil {
+			fmt.Println("Could not load spells:", err)
+			os.Exit(1)
+		}
+		limit := spellcasting.PreparedLimit(char.Class, char.Level, combat.CalculateSpellcastingStats(&char, characterModel.NewCharacterService()).AbilityMod)
+
+		var result string
+		switch cmd {
+		case "prepare-spell":
+			foundSpell := spellcasting.FindSpell(classSpells, *spellName)
+			if foundSpell == nil {
+				fmt.Printf("spell '%s' not found for class %s%s\n", *spellName, char.Class, lookup.DidYouMean(spellcasting.SuggestSpells(classSpells, *spellName)))
+				os.Exit(1)
+			}
+			result = spellcasting.PrepareSpell(&sc, *foundSpell, classSpells, limit)
+		case "unprepare-spell":
+			result = spellcasting.UnprepareSpell(&sc, *spellName)
+		case "prepare-set":
+			var set []spellcasting.Spell
+			for _, n := range splitList(*spellList) {
+				foundSpell := spellcasting.FindSpell(classSpells, n)
+				if foundSpell == nil {
+					fmt.Printf("spell '%s' not found for class %s%s\n", n, char.Class, lookup.DidYouMean(spellcasting.SuggestSpells(classSpells, n)))
+					os.Exit(1)
+				}
+				set = append(set, *foundSpell)
+			}
+			if err := spellcasting.PrepareSet(&sc, set, classSpells, limit); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			result = fmt.Sprintf("Prepared %d/%d spells: %s", spellcasting.PreparedCount(&sc, classSpells), limit, strings.Join(sc.PreparedSpells, ", "))
+		}
+		char.Spellcasting = sc
+		recalculateCombatStats(&char, characterModel.NewCharacterService())
+		err = characterStorage.Save(char)
+		if err != nil {
+			fmt.Printf("error saving character: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(result)
+		return
+
+	case "spells":
+		spellsCmd := flag.NewFlagSet("spells", flag.ExitOnError)
+		class := spellsCmd.String("class", "", "only spells on this class's spell list")
+		level := spellsCmd.Int("level", -1, "only spells of this level, 0 for cantrips")
+		school := spellsCmd.String("school", "", "only spells of this school")
+		ritual := spellsCmd.Bool("ritual", false, "only spells that can be cast as rituals")
+		search := spellsCmd.String("search", "", "only spells with this text in their name")
+		spellsCmd.Parse(os.Args[2:])
+		if *level > 9 {
+			fmt.Println("-level must be between 0 and 9")
+			os.Exit(2)
+		}
+		spells, err := spellcasting.LoadSpells("5e-SRD-Spells.csv")
+		if err != nil {
+			fmt.Println("Could not load spells:", err)
+			os.Exit(1)
+		}
+		index := spellcasting.NewSpellIndex(spells)
+		if *class != "" && !slices.Contains(index.Classes(), strings.ToLower(strings.TrimSpace(*class))) {
+			fmt.Printf("no spell list for class '%s'%s\n", *class, lookup.DidYouMean(lookup.Suggest(*class, index.Classes(), 3)))
+			os.Exit(1)
+		}
+		if *school != "" && !spellcasting.IsSchool(*school) {
+			fmt.Printf("unknown school '%s'%s\n", *school, lookup.DidYouMean(lookup.Suggest(*school, spellcasting.Schools, 3)))
+			os.Exit(1)
+		}
+		found := index.Query(spellcasting.SpellQuery{Class: *class, Level: *level, School: *school, Ritual: *ritual, Text: *search})
+		if len(found) == 0 {
+			suggestions := ""
+			if *search != "" {
+				suggestions = lookup.DidYouMean(spellcasting.SuggestSpells(spells, *search))
+			}
+			fmt.Printf("no spells found%s\n", suggestions)
+			return
+		}
+		fmt.Printf("%d spell(s):\n", len(found))
+		for _, s := range found {
+			fmt.Printf("  %s\n", spellcasting.FormatSpell(s))
+		}
+
+	case "auto-spells":
+		autoCmd := flag.NewFlagSet("auto-spells", flag.ExitOnError)
+		name := autoCmd.String("name", "", "character name (required)")
+		seed := autoCmd.Int64("seed", 0, "random seed for reproducible picks (random if 0)")
+		prefer := autoCmd.String("prefer", "", "comma separated schools, or the tag \"ritual\", to pick more often")
+		weight := autoCmd.Int("weight", 3, "how many times likelier a preferred spell is picked")
+		autoCmd.Parse(os.Args[2:])
+		if *name == "" {
+			fmt.Println("-name is required")
+			autoCmd.Usage()
+			os.Exit(2)
+		}
+		characterStorage := storage.NewSingleFileStorage("characters.json")
+		char, err := characterStorage.Load(*name)
+		if err != nil {
+			fmt.Printf("character \"%s\" not found\n", *name)
+			os.Exit(1)
+		}
+		sc := spellcasting.ForCharacter(&char)
+		if !spellcasting.CanCastSpells(sc.CasterType) {
+			fmt.Printf("%s can't cast spells\n", char.Name)
+			os.Exit(1)
+		}
+		classSpells, err := loadClassSpells(char.Class)
+		if err != nil {
+			fmt.Println("Could not load spells:", err)
+			os.Exit(1)
+		}
+		opts := spellcasting.AutoOptions{Prefer: splitList(*prefer), Weight: *weight}
+		for _, p := range opts.Prefer {
+			if !strings.EqualFold(p, "ritual") && !spellcasting.IsSchool(p) {
+				fmt.Printf("unknown school or tag '%s'\n", p)
+				os.Exit(1)
+			}
+		}
+		if *seed == 0 {
+			*seed = time.Now().UnixNano()
+		}
+		limit := spellcasting.PreparedLimit(char.Class, char.Level, combat.CalculateSpellcastingStats(&char, characterModel.NewCharacterService()).AbilityMod)
+		added := spellcasting.AutoAssign(&sc, classSpells, char.Class, char.Level, limit, rand.New(rand.NewSource(*seed)), opts)
+		char.Spellcasting = sc
+		recalculateCombatStats(&char, characterModel.NewCharacterService())
+		err = characterStorage.Save(char)
+		if err != nil {
+			fmt.Printf("error saving character: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Picked spells for %s (seed %d)\n", char.Name, *seed)
+		if spellcasting.GetCantripsKnown(char.Class, char.Level) > 0 {
+			fmt.Printf("  Cantrips: %s\n", displayOrNone(strings.Join(added.Cantrips, ", ")))
+		}
+		if sc.Spellbook != nil {
+			fmt.Printf("  Spellbook: %s\n", displayOrNone(strings.Join(added.Spellbook, ", ")))
+		}
+		if sc.CasterType == spellcasting.CasterKnown || sc.CasterType == spellcasting.CasterPact {
+			fmt.Printf("  Known: %s\n", displayOrNone(strings.Join(added.Known, ", ")))
+		} else {
+			fmt.Printf("  Prepared: %s\n", displayOrNone(strings.Join(added.Prepared, ", ")))
+		}
+
+	case "spellbook":
+		bookCmd := flag.NewFlagSet("spellbook", flag.ExitOnError)
+		name := bookCmd.String("name", "", "character name (required)")
+		add := bookCmd.String("add", "", "spell gained by leveling to write in the spellbook")
+		copySpell := bookCmd.String("copy", "", "spell to copy from a scroll or another spellbook, paid from the wallet")
+		bookCmd.Parse(os.Args[2:])
+		if *name == "" || (*add != "" && *copySpell != "") {
+			fmt.Println("-name is required, with at most one of -add and -copy")
+			os.Exit(2)
+		}
+		characterStorage := storage.NewSingleFileStorage("characters.json")
+		char, err := characterStorage.Load(*name)
+		if err != nil {
+			fmt.Printf("character \"%s\" not found\n", *name)
+			os.Exit(1)
+		}
+		sc := spellcasting.ForCharacter(&char)
+		if sc.Spellbook == nil {
+			fmt.Printf("%s doesn't keep a spellbook\n", char.Name)
+			os.Exit(1)
+		}
+		if *add == "" && *copySpell == "" {
+			fmt.Print(spellcasting.FormatSpellbook(&sc, char.Level))
+			return
+		}
+		classSpells, err := loadClassSpells(char.Class)
+		if err != nil {
+			fmt.Println("Could not load spells:", err)
+			os.Exit(1)
+		}
+		spellName := *add + *copySpell
+		foundSpell := spellcasting.FindSpell(classSpells, spellName)
+		if foundSpell == nil {
+			fmt.Printf("spell '%s' not found for class %s%s\n", spellName, char.Class, lookup.DidYouMean(spellcasting.SuggestSpells(classSpells, spellName)))
+			os.Exit(1)
+		}
+		var result string
+		if *add != "" {
+			if err := spellcasting.AddToSpellbook(&sc, *foundSpell, char.Level); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			result = "Wrote " + strings.ToLower(foundSpell.Name) + " in the spellbook"
+		} else {
+			cost, hours := spellcasting.CopyCost(*foundSpell)
+			if err := spellcasting.CopySpell(&sc, *foundSpell); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			if err := currency.Pay(&char.Wallet, cost); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			currency.Record(&char, "copy", strings.ToLower(foundSpell.Name), 1, cost)
+			result = fmt.Sprintf("Copied %s into the spellbook for %s and %d hours of work", strings.ToLower(foundSpell.Name), currency.FormatCopper(cost), hours)
+		}
+		char.Spellcasting = sc
+		err = characterStorage.Save(char)
+		if err != nil {
+			fmt.Printf("error saving character: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(result)
+
+	case "cast":
+		castCmd := flag.NewFlagSet("cast", flag.ExitOnError)
+		name := castCmd.String("name", "", "character name (required)")
+		spellName := castCmd.String("spell", "", "spell name (required)")
+		slot := castCmd.Int("slot", 0, "spell slot level (defaults to the lowest slot left that can cast the spell)")
+		ritual := castCmd.Bool("ritual", false, "cast the spell as a ritual, without a slot")
+		castCmd.Parse(os.Args[2:])
+		if *name == "" || *spellName == "" || (*ritual && *slot != 0) {
+			fmt.Println("-name and -spell are required; -ritual doesn't take a -slot")
+			os.Exit(2)
+		}
+		characterStorage := storage.NewSingleFileStorage("characters.json")
+		char, err := characterStorage.Load(*name)
+		if err != nil {
+			fmt.Printf("character \"%s\" not found\n", *name)
+			os.Exit(1)
+		}
+		if reason := combat.SpellcastingBlocked(&char); reason != "" {
+			fmt.Printf("%s can't cast spells: %s\n", char.Name, reason)
+			os.Exit(1)
+		}
+		spells, err := spellcasting.LoadSpells("5e-SRD-Spells.csv")
+		if err != nil {
+			fmt.Println("Could not load spells:", err)
+			os.Exit(1)
+		}
+		spell := spellcasting.FindSpell(spells, *spellName)
+		if spell == nil {
+			fmt.Printf("spell '%s' not found%s\n", *spellName, lookup.DidYouMean(spellcasting.SuggestSpells(spells, *spellName)))
+			os.Exit(1)
+		}
+		sc := spellcasting.ForCharacter(&char)
+		used := 0
+		if *ritual {
+			spellcasting.EnrichSpell(spell)
+			err = spellcasting.CastRitual(&sc, *spell, char.Class)
+		} else {
+			used, err = spellcasting.Cast(&sc, *spell, *slot)
+		}
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		ended := spellcasting.Concentrate(&char, *spell)
+		char.Spellcasting = sc
+		err = characterStorage.Save(char)
+		if err != nil {
+			fmt.Printf("error saving character: %v\n", err)
+			os.Exit(1)
+		}
+		switch {
+		case *ritual:
+			fmt.Printf("%s casts %s as a ritual without using a spell slot (casting time: %s)\n", char.Name, strings.ToLower(spell.Name),
+				spellcasting.RitualCastingTime(spell.CastingTime))
+		case spell.Level == 0:
+			fmt.Printf("%s casts %s\n", char.Name, strings.ToLower(spell.Name))
+		default:
+			fmt.Printf("%s casts %s with a level %d slot (%d/%d left)\n", char.Name, strings.ToLower(spell.Name), used,
+				spellcasting.RemainingSlots(&sc, used), sc.SpellSlots[used])
+		}
+		if damage, err := combat.CalculateSpellDamage(&char, characterModel.NewCharacterService(), *spell, used); err == nil {
+			fmt.Printf("Damage: %s\n", combat.FormatSpellDamageRoll(damage))
+		}
+		if ended != "" {
+			fmt.Printf("Concentration on %s ends\n", strings.ToLower(ended))
+		}
+		if spell.Concentration {
+			fmt.Printf("Concentrating on %s\n", strings.ToLower(spell.Name))
+		}
+
+	case "damage":
+		damageCmd := flag.NewFlagSet("damage", flag.ExitOnError)
+		name := damageCmd.String("name", "", "character name (required)")
+		amount := damageCmd.Int("amount", 0, "damage taken (required)")
+		saveTotal := damageCmd.String("save", "", "Constitution save total (d20 plus modifier) rolled at the table to keep concentrating")
+		roll := damageCmd.Bool("roll", false, "roll the concentration save instead of asking for it")
+		critical := damageCmd.Bool("crit", false, "the damage is from a critical hit (two death save failures at 0 hit points)")
+		seed := damageCmd.Int64("seed", 0, "random seed for the concentration save, for repeatable rolls")
+		damageCmd.Parse(os.Args[2:])
+		if *name == "" || *amount <= 0 || (*saveTotal != "" && *roll) {
+			fmt.Println("-name and a positive -amount are required, with at most one of -save and -roll")
+			damageCmd.Usage()
+			os.Exit(2)
+		}
+		characterStorage := storage.NewSingleFileStorage("characters.json")
+		char, err := characterStorage.Load(*name)
+		if err != nil {
+			fmt.Printf("character \"%s\" not found\n", *name)
+			os.Exit(1)
+		}
+		result, err := combat.ApplyDamage(&char, *amount, *critical)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s takes %d damage", char.Name, *amount)
+		if result.Absorbed > 0 {
+			fmt.Printf(" (%d absorbed by temporary hit points)", result.Absorbed)
+		}
+		fmt.Printf(": %d/%d hit points\n", result.HitPointsNow, condition.MaxHP(&char))
+		if result.Dropped != "" {
+			fmt.Printf("Concentration on %s ends\n", strings.ToLower(result.Dropped))
+		}
+		switch {
+		case result.MassiveDamage:
+			fmt.Printf("%s dies from massive damage: %d damage left over at 0 hit points reaches the %d hit point maximum\n",
+				char.Name, *amount-result.Absorbed-result.Lost, condition.MaxHP(&char))
+		case result.Died:
+			fmt.Printf("%s takes a third death save failure and dies\n", char.Name)
+		case result.FailedSaves > 0:
+			fmt.Printf("Damage at 0 hit points: %d death save failure(s), %s\n", result.FailedSaves, combat.FormatDeathState(&char))
+		case result.Dying:
+			fmt.Printf("%s falls unconscious and is dying; roll death saves with %s death-save -name \"%s\"\n", char.Name, os.Args[0], char.Name)
+		}
+		if result.ConcentrationDC > 0 {
+			service := characterModel.NewCharacterService()
+			mod := combat.CalculateSavingThrow(&char, service, "con")
+			total := 0
+			var autoFail []string
+			if *saveTotal == "" && !*roll {
+				fmt.Printf("Constitution save DC %d to keep concentrating on %s (%+d); enter the total or leave empty to roll: ",
+					result.ConcentrationDC, strings.ToLower(char.Concentration), mod)
+				line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+				*saveTotal = strings.TrimSpace(line)
+			}
+			if *saveTotal != "" {
+				if total, err = strconv.Atoi(*saveTotal); err != nil {
+					fmt.Printf("invalid save total %q\n", *saveTotal)
+					os.Exit(1)
+				}
+			} else {
+				save, err := combat.RollSave(&char, service, "con", false, false, dice.NewSource(*seed))
+				if err != nil {
+					fmt.Println(err)
+					os.Exit(1)
+				}
+				total, autoFail = save.Total(), save.AutoFail
+				fmt.Printf("Rolled %s\n", combat.FormatD20Roll(save))
+			}
+			spell := char.Concentration
+			if len(autoFail) > 0 {
+				char.Concentration = ""
+				fmt.Printf("Save fails automatically (%s): concentration on %s ends\n", strings.Join(autoFail, ", "), strings.ToLower(spell))
+			} else if combat.ConcentrationSave(&char, total, result.ConcentrationDC) {
+				fmt.Printf("Save %d vs DC %d succeeds: still concentrating on %s\n", total, result.ConcentrationDC, strings.ToLower(spell))
+			} else {
+				fmt.Printf("Save %d vs DC %d fails: concentration on %s ends\n", total, result.ConcentrationDC, strings.ToLower(spell))
+			}
+		}
+		recalculateCombatStats(&char, characterModel.NewCharacterService())
+		err = characterStorage.Save(char)
+		if err != nil {
+			fmt.Printf("error saving character: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "heal", "death-save":
+		hpCmd := flag.NewFlagSet(cmd, flag.ExitOnError)
+		name := hpCmd.String("name", "", "character name (required)")
+		amount := hpCmd.Int("amount", 0, "hit points regained")
+		seed := hpCmd.Int64("seed", 0, "random seed, for repeatable rolls")
+		hpCmd.Parse(os.Args[2:])
+		if *name == "" || (cmd == "heal" && *amount <= 0) {
+			fmt.Println("-name is required, and heal needs a positive -amount")
+			hpCmd.Usage()
+			os.Exit(2)
+		}
+		characterStorage := storage.NewSingleFileStorage("characters.json")
+		char, err := characterStorage.Load(*name)
+		if err != nil {
+			fmt.Printf("character \"%s\" not found\n", *name)
+			os.Exit(1)
+		}
+
+		switch cmd {
+		case "heal":
+			wasDown := char.CurrentHP == 0
+			healed, err := combat.Heal(&char, *amount)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			fmt.Printf("%s regains %d hit points: %d/%d\n", char.Name, healed, char.CurrentHP, condition.MaxHP(&char))
+			if wasDown {
+				fmt.Printf("%s wakes up; death saves reset\n", char.Name)
+			}
+		case "death-save":
+			result, err := combat.RollDeathSave(&char, dice.NewSource(*seed))
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			fmt.Printf("%s: %s\n", char.Name, combat.FormatD20Roll(result.Roll))
+			switch {
+			case result.Revived:
+				fmt.Printf("%s regains 1 hit point and wakes up\n", char.Name)
+			case result.Stable:
+				fmt.Printf("Third success: %s is stable at 0 hit points\n", char.Name)
+			case result.Died:
+				fmt.Printf("Third failure: %s dies\n", char.Name)
+			default:
+				fmt.Printf("%s is %s\n", char.Name, combat.FormatDeathState(&char))
+			}
+		}
+
+		recalculateCombatStats(&char, characterModel.NewCharacterService())
+		err = characterStorage.Save(char)
+		if err != nil {
+			fmt.Printf("error saving character: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "short-rest", "long-rest", "use-resource":
+		restCmd := flag.NewFlagSet(cmd, flag.ExitOnError)
+		name := restCmd.String("name", "", "character name (required)")
+		dice := restCmd.Int("dice", 0, "hit dice to spend on a short rest")
+		average := restCmd.Bool("average", false, "take the average of each hit die instead of rolling")
+		resource := restCmd.String("resource", "", "class resource to use, e.g. Rage")
+		amount := restCmd.Int("amount", 1, "uses of the resource to spend")
+		restCmd.Parse(os.Args[2:])
+		if *name == "" {
+			fmt.Println("-name is required")
+			restCmd.Usage()
+			os.Exit(2)
+		}
+		characterStorage := storage.NewSingleFileStorage("characters.json")
+		char, err := characterStorage.Load(*name)
+		if err != nil {
+			fmt.Printf("character \"%s\" not found\n", *name)
+			os.Exit(1)
+		}
+		if char.Dead {
+			fmt.Printf("%s is dead\n", char.Name)
+			os.Exit(1)
+		}
+		characterService := characterModel.NewCharacterService()
+		if missingClassAndRaceData(&char) {
+			if err := loadClassAndRaceData(&char, characterService); err != nil {
+				fmt.Printf("could not load class data: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		switch cmd {
+		case "short-rest":
+			rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+			result, err := rest.ShortRest(&char, characterService, *dice, *average, rng)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			fmt.Printf("%s takes a short rest\n", char.Name)
+			if len(result.Rolls) > 0 {
+				fmt.Printf("  spent %d hit dice: %v, healed %d\n", len(result.Rolls), result.Rolls, result.Healed)
+			}
+			if result.PactSlots {
+				fmt.Println("  Pact Magic slots restored")
+			}
+			if len(result.Recharged) > 0 {
+				fmt.Printf("  recharged %s\n", strings.Join(result.Recharged, ", "))
+			}
+			fmt.Printf("  hit points %d/%d, hit dice %d/%d\n", char.CurrentHP, condition.MaxHP(&char), result.DiceLeft, char.Level)
+		case "long-rest":
+			result, err := rest.LongRest(&char)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			fmt.Printf("%s takes a long rest\n", char.Name)
+			fmt.Printf("  healed %d, hit points %d/%d\n", result.Healed, char.CurrentHP, condition.MaxHP(&char))
+			fmt.Printf("  regained %d hit dice, %d/%d left\n", result.DiceRecovered, char.HitDice, char.Level)
+			if result.SlotsRestored {
+				fmt.Println("  spell slots restored")
+			}
+			if len(result.Recharged) > 0 {
+				fmt.Printf("  recharged %s\n", strings.Join(result.Recharged, ", "))
+			}
+			fmt.Printf("  exhaustion level %d\n", result.Exhaustion)
+		case "use-resource":
+			used, err := rest.UseResource(&char, *resource, *amount)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			fmt.Printf("%s uses %s: %d/%d left until a %s rest\n", char.Name, used.Name, used.Remaining, used.Max, used.Recharge)
+		}
+
+		recalculateCombatStats(&char, characterService)
+		err = characterStorage.Save(char)
+		if err != nil {
+			fmt.Printf("error saving character: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "check", "save", "attack":
+		rollCmd := flag.NewFlagSet(cmd, flag.ExitOnError)
+		name := rollCmd.String("name", "", "character name (required)")
+		skill := rollCmd.String("skill", "", "skill to check, e.g. stealth or sleight-of-hand")
+		ability := rollCmd.String("ability", "", "ability to save with, e.g. dex")
+		weapon := rollCmd.String("weapon", "", "weapon slot to attack with: mainhand or offhand")
+		targetAC := rollCmd.Int("ac", 0, "target's armor class, to tell hits from misses")
+		adv := rollCmd.Bool("adv", false, "roll with advantage")
+		dis := rollCmd.Bool("dis", false, "roll with disadvantage")
+		seed := rollCmd.Int64("seed", 0, "random seed, for repeatable rolls")
+		rollCmd.Parse(os.Args[2:])
+		required := map[string]*string{"check": skill, "save": ability, "attack": weapon}
+		flagName := map[string]string{"check": "-skill", "save": "-ability", "attack": "-weapon"}
+		if *name == "" || *required[cmd] == "" {
+			fmt.Printf("-name and %s are required\n", flagName[cmd])
+			rollCmd.Usage()
+			os.Exit(2)
+		}
+		characterStorage := storage.NewSingleFileStorage("characters.json")
+		char, err := characterStorage.Load(*name)
+		if err != nil {
+			fmt.Printf("character \"%s\" not found\n", *name)
+			os.Exit(1)
+		}
+		characterService := characterModel.NewCharacterService()
+		if missingClassAndRaceData(&char) {
+			if err := loadClassAndRaceData(&char, characterService); err != nil {
+				fmt.Printf("could not load class data: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		rng := dice.NewSource(*seed)
+
+		switch cmd {
+		case "check":
+			roll, err := combat.RollCheck(&char, characterService, *skill, *adv, *dis, rng)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			fmt.Printf("%s: %s\n", char.Name, combat.FormatD20Roll(roll))
+		case "save":
+			roll, err := combat.RollSave(&char, characterService, *ability, *adv, *dis, rng)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			fmt.Printf("%s: %s\n", char.Name, combat.FormatD20Roll(roll))
+		case "attack":
+			roll, err := combat.RollAttack(&char, characterService, *weapon, *targetAC, *adv, *dis, rng)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			fmt.Printf("%s: %s", char.Name, combat.FormatAttackRoll(roll))
+		}
+
+	case "condition":
+		if len(os.Args) < 3 {
+			fmt.Println("condition needs an action: add or remove")
+			os.Exit(2)
+		}
+		action := os.Args[2]
+		conditionCmd := flag.NewFlagSet("condition "+action, flag.ExitOnError)
+		name := conditionCmd.String("name", "", "character name (required)")
+		conditionName := conditionCmd.String("condition", "", "condition, e.g. poisoned, or exhaustion (required)")
+		levels := conditionCmd.Int("levels", 1, "exhaustion levels to add or remove")
+		conditionCmd.Parse(os.Args[3:])
+
+		if *name == "" || *conditionName == "" {
+			fmt.Println("-name and -condition are required")
+			conditionCmd.Usage()
+			os.Exit(2)
+		}
+
+		characterStorage := storage.NewSingleFileStorage("characters.json")
+		char, err := characterStorage.Load(*name)
+		if err != nil {
+			fmt.Printf("character \"%s\" not found\n", *name)
+			os.Exit(1)
+		}
+
+		switch action {
+		case "add":
+			err = condition.Add(&char, *conditionName, *levels)
+		case "remove":
+			err = condition.Remove(&char, *conditionName, *levels)
+		default:
+			fmt.Printf("unknown condition action '%s'\n", action)
+			os.Exit(2)
+		}
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if reason := condition.Incapacitated(&char); reason != "" && char.Concentration != "" {
+			fmt.Printf("Concentration on %s ends: %s is %s\n", strings.ToLower(char.Concentration), char.Name, reason)
+			char.Concentration = ""
+		}
+
+		recalculateCombatStats(&char, characterModel.NewCharacterService())
+		err = characterStorage.Save(char)
+		if err != nil {
+			fmt.Printf("error saving character: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s: %s %s\n", char.Name, action, strings.ToLower(*conditionName))
+		if len(char.ConditionNotes) == 0 {
+			fmt.Println("No conditions")
+		}
+		for _, note := range char.ConditionNotes {
+			fmt.Printf("  %s\n", note)
+		}
+		if char.Exhaustion >= condition.MaxExhaustion {
+			fmt.Printf("%s dies of exhaustion\n", char.Name)
+		}
+
+	case "conditions":
+		fmt.Println("Conditions:")
+		for _, name := range condition.Names() {
+			fmt.Printf("  %s: %s\n", name, condition.Conditions[name].Summary)
+		}
+		fmt.Println("Exhaustion levels:")
+		for level := 1; level <= condition.MaxExhaustion; level++ {
+			fmt.Printf("  %d: %s\n", level, condition.ExhaustionEffects[level])
+		}
+
+	case "encounter":
+		if len(os.Args) < 3 {
+			fmt.Println("encounter needs an action: start, status, next, damage, condition or end")
+			os.Exit(2)
+		}
+		action := os.Args[2]
+		encounterCmd := flag.NewFlagSet("encounter "+action, flag.ExitOnError)
+		characterNames := encounterCmd.String("characters", "", "stored characters taking part, comma separated")
+		monsterSpecs := encounterCmd.String("monsters", "", "ad-hoc monsters as NAME[*COUNT]:AC:HP[:DEX], comma separated; HP can be dice like 2d6")
+		seed := encounterCmd.Int64("seed", 0, "random seed, for repeatable rolls")
+		force := encounterCmd.Bool("force", false, "replace the encounter in progress")
+		target := encounterCmd.String("target", "", "participant to damage or change")
+		amount := encounterCmd.Int("amount", 0, "damage dealt")
+		critical := encounterCmd.Bool("crit", false, "the damage is from a critical hit")
+		addCondition := encounterCmd.String("add", "", "condition to give the target, or exhaustion")
+		removeCondition := encounterCmd.String("remove", "", "condition to end on the target, or exhaustion")
+		levels := encounterCmd.Int("levels", 1, "exhaustion levels to add or remove")
+		encounterCmd.Parse(os.Args[3:])
+
+		characterStorage := storage.NewSingleFileStorage("characters.json")
+		characterService := characterModel.NewCharacterService()
+		rng := dice.NewSource(*seed)
+
+		if action == "start" {
+			if _, err := encounter.Load(encounter.File); err == nil && !*force {
+				fmt.Println("an encounter is already in progress (end it, or use -force to replace it)")
+				os.Exit(1)
+			}
+			var characters []characterModel.Character
+			for _, n := range strings.Split(*characterNames, ",") {
+				if n = strings.TrimSpace(n); n == "" {
+					continue
+				}
+				char, err := characterStorage.Load(n)
+				if err != nil {
+					fmt.Printf("character \"%s\" not found\n", n)
+					os.Exit(1)
+				}
+				characters = append(characters, char)
+			}
+			var monsters []encounter.MonsterSpec
+			for _, spec := range strings.Split(*monsterSpecs, ",") {
+				if strings.TrimSpace(spec) == "" {
+					continue
+				}
+				m, err := encounter.ParseMonster(spec)
+				if err != nil {
+					fmt.Println(err)
+					os.Exit(2)
+				}
+				monsters = append(monsters, m)
+			}
+			enc, err := encounter.Start(characters, monsters, characterService, rng)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			if err := enc.Save(encounter.File); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			fmt.Println("Initiative:")
+			for _, p := range enc.Participants {
+				fmt.Printf("  %s: %s\n", p.Name, p.Roll)
+			}
+			fmt.Print(enc.Format(encounterSheet(characterStorage)))
+			fmt.Printf("%s goes first\n", enc.Current().Name)
+			return
+		}
+
+		enc, err := encounter.Load(encounter.File)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		enc.Refresh(encounterSheet(characterStorage))
+		switch action {
+		case "status":
+			fmt.Print(enc.Format(encounterSheet(characterStorage)))
+			return
+		case "end":
+			if err := encounter.End(encounter.File); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			fmt.Printf("Encounter over after %d round(s)\n", enc.Round)
+			return
+		case "next":
+			p, err := enc.Next()
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			fmt.Printf("Round %d: %s's turn\n", enc.Round, p.Name)
+			if char := encounterSheet(characterStorage)(p); char != nil {
+				fmt.Printf("  %s\n", p.Status(char))
+				if combat.Dying(char) {
+					fmt.Printf("  %s is dying: roll with %s death-save -name \"%s\"\n", p.Name, os.Args[0], p.Name)
+				} else if reason := condition.Incapacitated(char); reason != "" {
+					fmt.Printf("  %s is %s and can't take actions\n", p.Name, reason)
+				}
+			}
+		case "damage", "condition":
+			if *target == "" {
+				fmt.Println("-target is required")
+				encounterCmd.Usage()
+				os.Exit(2)
+			}
+			p, err := enc.Find(*target)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			char := p.Monster
+			if char == nil {
+				loaded, err := characterStorage.Load(p.Name)
+				if err != nil {
+					fmt.Printf("character \"%s\" not found\n", p.Name)
+					os.Exit(1)
+				}
+				char = &loaded
+			}
+
+			if action == "damage" {
+				if *amount <= 0 {
+					fmt.Println("-amount must be positive")
+					os.Exit(2)
+				}
+				result, err := p.ApplyDamage(char, *amount, *critical)
+				if err != nil {
+					fmt.Println(err)
+					os.Exit(1)
+				}
+				fmt.Printf("%s takes %d damage: %s\n", p.Name, *amount, p.Status(char))
+				if result.Dropped != "" {
+					fmt.Printf("  concentration on %s ends\n", strings.ToLower(result.Dropped))
+				}
+				if result.ConcentrationDC > 0 {
+					spell := char.Concentration
+					save, err := combat.RollSave(char, characterService, "con", false, false, rng)
+					if err != nil {
+						fmt.Println(err)
+						os.Exit(1)
+					}
+					outcome := "concentration ends"
+					if combat.ConcentrationSave(char, save.Total(), result.ConcentrationDC) {
+						outcome = "still concentrating on " + strings.ToLower(spell)
+					}
+					fmt.Printf("  concentration DC %d: %s, %s\n", result.ConcentrationDC, combat.FormatD20Roll(save), outcome)
+				}
+				switch {
+				case p.Monster != nil && char.Dead:
+					fmt.Printf("  %s is defeated\n", p.Name)
+				case result.MassiveDamage:
+					fmt.Printf("  %s dies from massive damage\n", p.Name)
+				case result.Died:
+					fmt.Printf("  %s dies\n", p.Name)
+				case result.FailedSaves > 0:
+					fmt.Printf("  %d death save failure(s)\n", result.FailedSaves)
+				case result.Dying:
+					fmt.Printf("  %s falls unconscious and is dying\n", p.Name)
+				}
+			} else {
+				switch {
+				case *addCondition != "" && *removeCondition == "":
+					err = condition.Add(char, *addCondition, *levels)
+				case *removeCondition != "" && *addCondition == "":
+					err = condition.Remove(char, *removeCondition, *levels)
+				default:
+					fmt.Println("give one of -add and -remove")
+					os.Exit(2)
+				}
+				if err != nil {
+					fmt.Println(err)
+					os.Exit(1)
+				}
+				if reason := condition.Incapacitated(char); reason != "" && char.Concentration != "" {
+					fmt.Printf("  concentration on %s ends: %s is %s\n", strings.ToLower(char.Concentration), p.Name, reason)
+					char.Concentration = ""
+				}
+				p.Out = char.Dead
+				fmt.Printf("%s: %s\n", p.Name, p.Status(char))
+			}
+
+			if p.Monster == nil {
+				recalculateCombatStats(char, characterService)
+				if err := characterStorage.Save(*char); err != nil {
+					fmt.Printf("error saving character: %v\n", err)
+					os.Exit(1)
+				}
+			}
+		default:
+			fmt.Printf("unknown encounter action '%s'\n", action)
+			os.Exit(2)
+		}
+		if err := enc.Save(encounter.File); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+	case "roll":
+		rollCmd := flag.NewFlagSet("roll", flag.ExitOnError)
+		expr := rollCmd.String("expr", "", "dice expression, e.g. 2d6+3, \"1d20+5 adv\", 4d6kh3, \"8d6 min 3\" or 1d8r1 (required)")
+		seed := rollCmd.Int64("seed", 0, "random seed, for repeatable rolls")
+		rollCmd.Parse(os.Args[2:])
+		if rollCmd.NArg() > 0 {
+			// allow unquoted expressions like: roll -expr 1d20+5 adv
+			*expr = strings.TrimSpace(*expr + " " + strings.Join(rollCmd.Args(), " "))
+		}
+		if *expr == "" {
+			fmt.Println("-expr is required")
+			rollCmd.Usage()
+			os.Exit(2)
+		}
+		result, err := dice.Roll(*expr, dice.NewSource(*seed))
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		fmt.Println(result)
+
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+// loadClassAndRaceData fills in a character's armor, weapon and saving throw proficiencies and base speed from the class and race data files
+func loadClassAndRaceData(char *characterModel.Character, service *characterModel.CharacterService) error {
+	classes, err := classModel.LoadClasses("classes.json")
+	if err != nil {
+		return err
+	}
+	races, err := raceModel.LoadRaces("races.json")
+	if err != nil {
+		return err
+	}
+
+	var selectedClass classModel.Class
+	for _, cls := range classes {
+		if strings.EqualFold(cls.Name, char.Class) {
+			selectedClass = cls
+			break
+		}
+	}
+	var selectedRace raceModel.Race
+	for _, r := range races {
+		if strings.EqualFold(r.Name, char.Race) {
+			selectedRace = r
+			break
+		}
+	}
+
+	char.ArmorProficiencies = service.CombineArmorProficiencies(selectedClass, selectedRace)
+	char.WeaponProficiencies = service.CombineWeaponProficiencies(selectedClass, selectedRace)
+	char.BaseSpeed = selectedRace.Speed
+	char.SavingThrows = nil
+	for _, save := range selectedClass.SavingThrows {
+		char.SavingThrows = append(char.SavingThrows, strings.ToLower(save))
+	}
+
+	// Hit points and hit dice start full the first time the class hit die is known
+	firstTime := char.HitDie == 0
+	char.HitDie = selectedClass.HitDie
+	char.MaxHP = service.HitPointMaximum(char.HitDie, char.Level, char.Con)
+	if firstTime {
+		char.CurrentHP = char.MaxHP
+		char.HitDice = char.Level
+	}
+	char.CurrentHP = min(char.CurrentHP, condition.MaxHP(char))
+	service.SyncResources(char, selectedClass)
+
+	// Domain and oath spells are prepared on top of the chosen ones
+	if char.Spellcasting != nil {
+		cs := spellcasting.ForCharacter(char)
+		cs.AlwaysPrepared = nil
+		if sub := selectedClass.FindSubclass(char.Subclass); sub != nil {
+			cs.AlwaysPrepared = sub.AlwaysPreparedAt(char.Level)
+		}
+		char.Spellcasting = cs
+	}
+	return nil
+}
+
+// encounterSheet returns a lookup of the character sheet for an encounter participant: the
+// monster's own, or the stored character's, nil when it can't be loaded
+func encounterSheet(characterStorage *storage.SingleFileStorage) func(p *encounter.Participant) *characterModel.Character {
+	return func(p *encounter.Participant) *characterModel.Character {
+		if p.Monster != nil {
+			return p.Monster
+		}
+		char, err := characterStorage.Load(p.Name)
+		if err != nil {
+			return nil
+		}
+		return &char
+	}
+}
+
+// missingClassAndRaceData reports whether a character was saved before some class or race data was stored on it
+func missingClassAndRaceData(char *characterModel.Character) bool {
+	return len(char.WeaponProficiencies) == 0 || len(char.SavingThrows) == 0 || char.BaseSpeed == 0 || char.HitDie == 0
+}
+
+// recalculateCombatStats refreshes the stored combat values after the character's equipment or inventory changed
+func recalculateCombatStats(char *characterModel.Character, service *characterModel.CharacterService) {
+	if equipmentList, err := equipment.LoadEquipmentFromCSV("5e-SRD-Equipment.csv"); err == nil {
+		char.CarriedWeight = equipment.CarriedWeight(char, equipmentList)
+	}
+	char.ArmorClass = combat.CalculateArmorClass(char, service)
+	char.Initiative = combat.CalculateInitiative(char, service)
+	char.PassivePerception = combat.CalculatePassivePerception(char, service)
+	char.Speed = combat.CalculateSpeed(char)
+	char.ReducedMaxHP = 0
+	if maxHP := condition.MaxHP(char); maxHP != char.MaxHP {
+		char.ReducedMaxHP = maxHP
+	}
+	char.ConditionNotes = condition.Notes(char)
+	char.StealthDisadvantage = combat.HasStealthDisadvantage(char)
+	char.Attacks = combat.CalculateAttacks(char, service)
+	char.Saves = combat.CalculateSavingThrows(char, service)
+	if spells, err := spellcasting.LoadSpells("5e-SRD-Spells.csv"); err == nil {
+		char.SpellDamage = combat.CalculateSpellDamageTable(char, service, spells)
+	}
+}
+
+// confirmProficiency refuses to equip an item the character isn't proficient with unless forced
+func confirmProficiency(char *characterModel.Character, slot string, itemName string, force bool) {
+	proficient := true
+	penalty := "attacks won't add the proficiency bonus"
+	switch slot {
+	case equipment.SlotMainHand, equipment.SlotOffHand:
+		proficient = equipment.IsWeaponProficient(char, itemName)
+	case equipment.SlotArmor, equipment.SlotShield:
+		proficient = equipment.IsArmorProficient(char, itemName)
+		penalty = "disadvantage on STR and DEX rolls and no spellcasting"
+	}
+	if proficient {
+		return
+	}
+	if !force {
+		fmt.Printf("%s is not proficient with %s (use -force to equip anyway)\n", char.Name, itemName)
+		os.Exit(1)
+	}
+	fmt.Printf("warning: %s is not proficient with %s: %s\n", char.Name, itemName, penalty)
+}
+
+// checkCatalogItem checks that the item is in the equipment catalog and fits the slot,
+// returning its catalog name
+func checkCatalogItem(items []equipment.EquipmentItem, slot string, name string) (string, error) {
+	entry := equipment.FindEquipmentByName(items, name)
+	if entry == nil {
+		return "", fmt.Errorf("unknown item %q: not in the equipment catalog%s", name, lookup.DidYouMean(equipment.SuggestEquipment(items, name)))
+	}
+	isShield := false
+	if stats, _ := equipment.LookupArmor(entry.Name); stats.Category == "shield" {
+		isShield = true
+	}
+	switch {
+	case (slot == equipment.SlotMainHand || slot == equipment.SlotOffHand) && entry.Category != "Weapon":
+		return "", fmt.Errorf("%s is not a weapon", entry.Name)
+	case slot == equipment.SlotArmor && (entry.Category != "Armor" || isShield):
+		return "", fmt.Errorf("%s is not armor", entry.Name)
+	case slot == equipment.SlotShield && !isShield:
+		return "", fmt.Errorf("%s is not a shield", entry.Name)
+	}
+	return strings.ToLower(entry.Name), nil
+}
+
+// loadClassSpells returns the SRD spells on the class's spell list
+func loadClassSpells(class string) ([]spellcasting.Spell, error) {
+	spells, err := spellcasting.LoadSpells("5e-SRD-Spells.csv")
+	if err != nil {
+		return nil, err
+	}
+	return spellcasting.FilterSpellsByClass(spells, class), nil
+}
+
+// splitList splits a comma separated flag value, dropping empty entries
+func splitList(value string) []string {
+	var parts []string
+	for _, p := range strings.Split(value, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return parts
+}
+
+// promptChooser asks for starting equipment choices on standard input
+type promptChooser struct {
+	in *bufio.Reader
+}
+
+func (p promptChooser) Choose(n int, options [][]classModel.StartingItem) (int, error) {
+	fmt.Printf("Starting equipment choice %d:\n", n+1)
+	for i, option := range options {
+		fmt.Printf("  (%s) %s\n", equipment.OptionLabel(i), equipment.FormatOption(option))
+	}
+	for {
+		fmt.Print("> ")
+		line, err := p.in.ReadString('\n')
+		if pick := equipment.ParseOptionLabel(line); pick >= 0 && pick < len(options) {
+			return pick, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("no option picked for choice %d", n+1)
+		}
+		fmt.Printf("pick one of a-%s\n", equipment.OptionLabel(len(options)-1))
+	}
+}
+
+func (p promptChooser) Weapon(placeholder string) (string, error) {
+	for {
+		fmt.Printf("Pick %s: ", placeholder)
+		line, err := p.in.ReadString('\n')
+		if name := strings.TrimSpace(line); name != "" {
+			matchErr := equipment.MatchesWeaponPlaceholder(placeholder, name)
+			if matchErr == nil {
+				return name, nil
+			}
+			fmt.Println(matchErr)
+		}
+		if err != nil {
+			return "", fmt.Errorf("no weapon picked for %s", placeholder)
+		}
+	}
+}
+
+// flagChooser takes starting equipment choices from the -choices and -weapons flags
+type flagChooser struct {
+	picks   []string
+	weapons []string
+}
+
+func (f *flagChooser) Choose(n int, options [][]classModel.StartingItem) (int, error) {
+	if n >= len(f.picks) {
+		var labels []string
+		for i, option := range options {
+			labels = append(labels, fmt.Sprintf("(%s) %s", equipment.OptionLabel(i), equipment.FormatOption(option)))
+		}
+		return 0, fmt.Errorf("-choices needs an option for choice %d: %s", n+1, strings.Join(labels, " or "))
+	}
+	pick := equipment.ParseOptionLabel(f.picks[n])
+	if pick < 0 {
+		return 0, fmt.Errorf("invalid option %q for choice %d", f.picks[n], n+1)
+	}
+	return pick, nil
+}
+
+func (f *flagChooser) Weapon(placeholder string) (string, error) {
+	if len(f.weapons) == 0 {
+		return "", fmt.Errorf("-weapons needs a weapon for %s", placeholder)
+	}
+	weapon := f.weapons[0]
+	f.weapons = f.weapons[1:]
+	return weapon, nil
+}
+
+// displayOrNone returns the value or "none" when it is empty
+func displayOrNone(value string) string {
+	if value == "" {
+		return "none"
 	}
+	return value
 }